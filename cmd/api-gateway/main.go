@@ -5,6 +5,8 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -12,6 +14,12 @@ import (
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
 )
 
+// internalWalletPaths são as rotas do wallet-service (após o prefixo /api/wallet) chamadas só
+// pelos serviços internos; o crédito de prêmios (payout) fica fora do gateway público
+var internalWalletPaths = []string{
+	"/wallet/payout",
+}
+
 func rp(to string) *httputil.ReverseProxy {
 	u, _ := url.Parse(to)
 	return httputil.NewSingleHostReverseProxy(u)
@@ -44,8 +52,14 @@ func main() {
 	// odds (ex.: /api/odds/* -> odds-service)
 	mux.Handle("/api/odds/", http.StripPrefix("/api/odds", odds))
 
+	// stream SSE de odds: cada evento é repassado ao cliente sem buffer
+	oddsStream := rp(oddsURL)
+	oddsStream.FlushInterval = -1
+	mux.Handle("/api/odds/v1/stream/", http.StripPrefix("/api/odds", oddsStream))
+
 	// wallet (ex.: /api/wallet/* -> wallet-service)
-	mux.Handle("/api/wallet/", http.StripPrefix("/api/wallet", wallet))
+	// o crédito de prêmios é chamado só pelos serviços internos
+	mux.Handle("/api/wallet/", http.StripPrefix("/api/wallet", blockPaths(wallet, internalWalletPaths...)))
 
 	// bets (ex.: /api/bets/* -> bet-service)
 	mux.Handle("/api/bets/", http.StripPrefix("/api/bets", bet))
@@ -83,10 +97,24 @@ func main() {
 	}
 }
 
+// blockPaths responde 404 para os caminhos informados (e subcaminhos) em vez de repassá-los a h
+func blockPaths(h http.Handler, paths ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := path.Clean("/" + r.URL.Path)
+		for _, b := range paths {
+			if p == b || strings.HasPrefix(p, b+"/") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func withCORS(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, Last-Event-ID")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -1,19 +1,22 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	kafkago "github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-confirmation/reconcile"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-confirmation/supplier"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-confirmation/wallet"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/config"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/db"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/kafka"
@@ -21,22 +24,13 @@ import (
 	ev "github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 )
 
-type betPlaced struct {
-	BetID      string  `json:"betId"`
-	UserID     string  `json:"userId"`
-	EventID    string  `json:"eventId"`
-	Market     string  `json:"market"`
-	Selection  string  `json:"selection"`
-	StakeCents int64   `json:"stake_cents"`
-	OddValue   float64 `json:"odd_value"`
-	ReservedID string  `json:"reserved_id,omitempty"`
-	CreatedAt  int64   `json:"created_at,omitempty"`
-}
-
-type supplierConfirmResp struct {
-	Status      string `json:"status"`
-	ProviderRef string `json:"providerRef"`
-	Reason      string `json:"reason,omitempty"`
+// commitFailure é publicado na DLQ de bet_confirmed quando a reserva não pôde ser efetivada
+type commitFailure struct {
+	BetID       string    `json:"betId"`
+	UserID      string    `json:"userId"`
+	ReservedRef string    `json:"reservedRef"`
+	Error       string    `json:"error"`
+	Ts          time.Time `json:"ts"`
 }
 
 func main() {
@@ -84,6 +78,62 @@ func main() {
 		defer dlqWriter.Close()
 	}
 
+	// DLQ para apostas confirmadas cuja reserva não pôde ser efetivada.
+	var commitDLQWriter *kafkago.Writer
+	if cfg.TopicBetConfirmedDLQ != "" {
+		commitDLQWriter = kafka.NewWriter(cfg.KafkaBrokers, cfg.TopicBetConfirmedDLQ)
+		defer commitDLQWriter.Close()
+	}
+
+	wcli := wallet.New(cfg.WalletBaseURL)
+
+	// Métricas de efetivação de reservas.
+	commitFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_confirmation_wallet_commit_failures_total",
+		Help: "reservas de apostas confirmadas enviadas à DLQ após falha no commit",
+	})
+	uncommittedGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bet_confirmation_uncommitted_reservations",
+		Help: "apostas aceitas com reserva ainda PENDING na última varredura",
+	})
+	uncommittedFixed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_confirmation_uncommitted_recommitted_total",
+		Help: "reservas pendentes efetivadas pela varredura",
+	})
+	stuckGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bet_confirmation_stuck_bets",
+		Help: "apostas presas em PENDING_CONFIRMATION na última varredura",
+	})
+	stuckResolved := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bet_confirmation_stuck_resolved_total",
+		Help: "apostas presas resolvidas pela reconciliação, por ação (requeried, resubmitted, rejected)",
+	}, []string{"action"})
+	stuckErrors := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_confirmation_stuck_errors_total",
+		Help: "falhas ao resolver apostas presas",
+	})
+	unpublishedGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bet_confirmation_unpublished_decisions",
+		Help: "apostas CONFIRMED/REJECTED sem bet_confirmed publicado na última varredura",
+	})
+	unpublishedFixed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_confirmation_republished_total",
+		Help: "eventos bet_confirmed republicados pela varredura",
+	})
+	prometheus.MustRegister(commitFailures, uncommittedGauge, uncommittedFixed, stuckGauge, stuckResolved, stuckErrors,
+		unpublishedGauge, unpublishedFixed)
+
+	deps := &workerDeps{
+		log:             log,
+		pg:              pg,
+		wallet:          wcli,
+		supplier:        supplier.New(cfg.SupplierWSURL),
+		confirmedWriter: confirmedWriter,
+		dlqWriter:       dlqWriter,
+		commitDLQWriter: commitDLQWriter,
+		onCommitFailure: commitFailures.Inc,
+	}
+
 	// Servidor HTTP para métricas e health.
 	go func() {
 		mux := http.NewServeMux()
@@ -110,6 +160,45 @@ func main() {
 
 	ctx := context.Background()
 
+	// Varredura periódica de apostas aceitas com reserva ainda não efetivada.
+	uncommitted := &reconcile.Uncommitted{
+		Log:         log,
+		DB:          pg,
+		Wallet:      wcli,
+		Interval:    cfg.UncommittedCheckInterval,
+		Grace:       cfg.UncommittedGrace,
+		OnFound:     func(n int) { uncommittedGauge.Set(float64(n)) },
+		OnCommitted: uncommittedFixed.Inc,
+	}
+	go uncommitted.Run(ctx)
+
+	// Varredura periódica de apostas presas em PENDING_CONFIRMATION.
+	stuck := &reconcile.Stuck{
+		Log:         log,
+		DB:          pg,
+		Supplier:    deps.supplier,
+		Apply:       deps.applyDecision,
+		Interval:    cfg.StuckCheckInterval,
+		Age:         cfg.StuckBetAge,
+		RejectAfter: cfg.StuckBetRejectAfter,
+		OnFound:     func(n int) { stuckGauge.Set(float64(n)) },
+		OnResolved:  func(action string) { stuckResolved.WithLabelValues(action).Inc() },
+		OnError:     stuckErrors.Inc,
+	}
+	go stuck.Run(ctx)
+
+	// Varredura periódica de decisões cujo bet_confirmed não foi publicado.
+	unpublished := &reconcile.Unpublished{
+		Log:           log,
+		DB:            pg,
+		Publish:       deps.publishConfirmed,
+		Interval:      cfg.UnpublishedCheckInterval,
+		Grace:         cfg.UnpublishedGrace,
+		OnFound:       func(n int) { unpublishedGauge.Set(float64(n)) },
+		OnRepublished: unpublishedFixed.Inc,
+	}
+	go unpublished.Run(ctx)
+
 	for {
 		msg, err := reader.ReadMessage(ctx)
 		if err != nil {
@@ -118,66 +207,120 @@ func main() {
 			continue
 		}
 
-		var placed betPlaced
+		var placed ev.BetPlaced
 		if jerr := json.Unmarshal(msg.Value, &placed); jerr != nil {
 			log.Error("unmarshal bet_placed", zap.Error(jerr))
 			continue
 		}
 
-		if err := processOne(ctx, log, pg, cfg, confirmedWriter, dlqWriter, &placed); err != nil {
+		if err := deps.processOne(ctx, &placed); err != nil {
 			log.Error("process bet", zap.String("betId", placed.BetID), zap.Error(err))
 			time.Sleep(500 * time.Millisecond)
 		}
 	}
 }
 
-func processOne(
-	ctx context.Context,
-	log *zap.Logger,
-	pg *sql.DB,
-	cfg config.Config,
-	confirmedWriter *kafkago.Writer,
-	dlqWriter *kafkago.Writer,
-	placed *betPlaced,
-) error {
+// workerDeps agrupa as dependências usadas no processamento de cada aposta
+type workerDeps struct {
+	log             *zap.Logger
+	pg              *sql.DB
+	wallet          *wallet.Client
+	supplier        *supplier.Client
+	confirmedWriter *kafkago.Writer
+	dlqWriter       *kafkago.Writer
+	commitDLQWriter *kafkago.Writer
+	onCommitFailure func()
+}
+
+func (d *workerDeps) processOne(ctx context.Context, placed *ev.BetPlaced) error {
+	// Entrega duplicada: a aposta já saiu de PENDING_CONFIRMATION, não consulta o supplier de novo.
+	cur, err := betstate.Current(ctx, d.pg, placed.BetID)
+	if err != nil {
+		return err
+	}
+	if cur != betstate.PendingConfirmation {
+		d.log.Info("skip bet already processed", zap.String("betId", placed.BetID), zap.String("status", cur))
+		if cur == betstate.Confirmed || cur == betstate.Rejected {
+			// a entrega anterior pode ter caído entre a transição e a publicação
+			return d.republish(ctx, placed.BetID)
+		}
+		return nil
+	}
+
 	// Chamada ao supplier com retries simples.
-	sresp, err := callSupplierConfirm(ctx, cfg, placed)
+	sresp, err := d.supplier.Confirm(ctx, placed)
 	if err != nil {
 		const retries = 3
 		for i := 0; i < retries; i++ {
 			time.Sleep(time.Duration(300*(i+1)) * time.Millisecond)
-			if sresp, err = callSupplierConfirm(ctx, cfg, placed); err == nil {
+			if sresp, err = d.supplier.Confirm(ctx, placed); err == nil {
 				break
 			}
 		}
 		if err != nil {
-			if dlqWriter != nil {
-				_ = kafka.WriteJSON(ctx, dlqWriter, placed.BetID, mustJSON(placed))
+			if d.dlqWriter != nil {
+				_ = kafka.WriteJSON(ctx, d.dlqWriter, placed.BetID, mustJSON(placed))
 			}
 			return err
 		}
 	}
 
+	_, err = d.applyDecision(ctx, placed, sresp)
+	return err
+}
+
+// applyDecision aplica a decisão do fornecedor: transição PENDING_CONFIRMATION -> CONFIRMED|REJECTED,
+// efetivação ou estorno da reserva e publicação de bet_confirmed.
+// Retorna false se a aposta já havia saído de PENDING_CONFIRMATION.
+func (d *workerDeps) applyDecision(ctx context.Context, placed *ev.BetPlaced, sresp *supplier.ConfirmResp) (bool, error) {
 	// Atualização do status da aposta.
 	newStatus := strings.ToUpper(sresp.Status)
-	if newStatus != "CONFIRMED" && newStatus != "REJECTED" {
-		newStatus = "REJECTED"
+	if newStatus != betstate.Confirmed && newStatus != betstate.Rejected {
+		newStatus = betstate.Rejected
 	}
-	if err := updateBetStatus(ctx, pg, placed.BetID, newStatus); err != nil {
-		return err
+	ok, err := d.transition(ctx, placed.BetID, newStatus, sresp)
+	if err != nil {
+		return false, err
 	}
-	if err := insertBetTransaction(ctx, pg, placed.BetID, "PENDING_CONFIRMATION", newStatus, sresp.Reason); err != nil {
-		log.Warn("bet_tx insert", zap.Error(err))
+	if !ok {
+		// outra entrega (ou a reconciliação) alterou a aposta durante a consulta ao supplier
+		d.log.Info("bet status changed concurrently", zap.String("betId", placed.BetID))
+		return false, nil
 	}
 
-	// Estorno de saldo em caso de rejeição.
-	if newStatus == "REJECTED" {
-		if err := walletRefund(ctx, cfg, placed.UserID, placed.StakeCents, "bet-reject:"+placed.BetID); err != nil {
-			log.Error("wallet refund", zap.Error(err))
+	// A reserva foi feita com external_ref = betID (ReservedRef).
+	reservedRef := placed.ReservedRef
+	if reservedRef == "" {
+		reservedRef = placed.BetID
+	}
+
+	switch newStatus {
+	case betstate.Confirmed:
+		// Efetivação da reserva; em falha definitiva, segue para a DLQ e para a varredura de pendentes.
+		if err := d.wallet.CommitWithRetry(ctx, placed.UserID, reservedRef, 3); err != nil {
+			d.log.Error("wallet commit", zap.String("betId", placed.BetID), zap.Error(err))
+			if d.onCommitFailure != nil {
+				d.onCommitFailure()
+			}
+			if d.commitDLQWriter != nil {
+				_ = kafka.WriteJSON(ctx, d.commitDLQWriter, placed.BetID, mustJSON(commitFailure{
+					BetID:       placed.BetID,
+					UserID:      placed.UserID,
+					ReservedRef: reservedRef,
+					Error:       err.Error(),
+					Ts:          time.Now(),
+				}))
+			}
+		}
+	case betstate.Rejected:
+		// Estorno de saldo em caso de rejeição.
+		if err := d.wallet.Refund(ctx, placed.UserID, reservedRef); err != nil {
+			d.log.Error("wallet refund", zap.Error(err))
 		}
 	}
 
-	// Publicação do evento bet_confirmed.
+	// Publicação do evento bet_confirmed; se falhar (ou o worker cair antes), a varredura
+	// de decisões não publicadas republica.
 	evc := ev.BetConfirmed{
 		BetID:       placed.BetID,
 		UserID:      placed.UserID,
@@ -186,84 +329,47 @@ func processOne(
 		ProviderRef: sresp.ProviderRef,
 		Ts:          time.Now(),
 	}
-	return kafka.WriteJSON(ctx, confirmedWriter, placed.BetID, mustJSON(evc))
+	return true, d.publishConfirmed(ctx, evc)
 }
 
-func callSupplierConfirm(ctx context.Context, cfg config.Config, p *betPlaced) (*supplierConfirmResp, error) {
-	body, _ := json.Marshal(map[string]any{
-		"betId":       p.BetID,
-		"userId":      p.UserID,
-		"eventId":     p.EventID,
-		"stake_cents": p.StakeCents,
-		"odd_value":   p.OddValue,
-	})
-
-	// Derivação da base HTTP a partir da URL de WS do supplier.
-	base := cfg.SupplierWSURL
-	base = strings.Replace(base, "ws://", "http://", 1)
-	base = strings.TrimSuffix(base, "/ws")
-	url := base + "/supplier/confirm"
-
-	// Cliente HTTP com timeout.
-	httpClient := &http.Client{Timeout: 5 * time.Second}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := httpClient.Do(req)
+// transition aplica PENDING_CONFIRMATION -> newStatus e grava a referência do fornecedor
+// na mesma transação, para que a decisão possa ser republicada
+func (d *workerDeps) transition(ctx context.Context, betID, newStatus string, sresp *supplier.ConfirmResp) (bool, error) {
+	tx, err := d.pg.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		return nil, errors.New("supplier http " + resp.Status)
+		return false, err
 	}
+	defer tx.Rollback()
 
-	var out supplierConfirmResp
-	if jerr := json.NewDecoder(resp.Body).Decode(&out); jerr != nil {
-		return nil, jerr
+	ok, err := betstate.TransitionTx(ctx, tx, betID, betstate.PendingConfirmation, newStatus, sresp.Reason)
+	if err != nil || !ok {
+		return false, err
 	}
-	return &out, nil
-}
-
-func updateBetStatus(ctx context.Context, pg *sql.DB, betID, status string) error {
-	_, err := pg.ExecContext(ctx, `UPDATE bets SET status=$1, updated_at=NOW() WHERE id=$2`, status, betID)
-	return err
-}
-
-func insertBetTransaction(ctx context.Context, pg *sql.DB, betID, oldStatus, newStatus, reason string) error {
-	_, err := pg.ExecContext(ctx, `
-		INSERT INTO bet_transactions (bet_id, old_status, new_status, reason, created_at)
-		VALUES ($1,$2,$3,$4,NOW())`, betID, oldStatus, newStatus, reason)
-	return err
+	if sresp.ProviderRef != "" {
+		if _, err := tx.ExecContext(ctx, `UPDATE bets SET provider_ref=$2 WHERE id=$1`, betID, sresp.ProviderRef); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
 }
 
-func walletRefund(ctx context.Context, cfg config.Config, userID string, amount int64, ext string) error {
-	payload, _ := json.Marshal(map[string]any{
-		"userId":       userID,
-		"amount_cents": amount,
-		"external_ref": ext,
-	})
-
-	// URL do wallet parametrizável por config; padrão para ambiente Docker.
-	walletBase := cfg.WalletBaseURL
-	if walletBase == "" {
-		walletBase = "http://wallet-service:8082"
+// publishConfirmed publica bet_confirmed e marca a decisão como publicada.
+// Republicações usam a mesma chave (betID); consumidores tratam o evento como idempotente
+func (d *workerDeps) publishConfirmed(ctx context.Context, evc ev.BetConfirmed) error {
+	if err := kafka.WriteJSON(ctx, d.confirmedWriter, evc.BetID, mustJSON(evc)); err != nil {
+		return err
 	}
-	url := strings.TrimRight(walletBase, "/") + "/wallet/refund"
-
-	httpClient := &http.Client{Timeout: 5 * time.Second}
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
-	req.Header.Set("Content-Type", "application/json")
+	return reconcile.MarkPublished(ctx, d.pg, evc.BetID)
+}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
+// republish publica o bet_confirmed de uma aposta já decidida, se ainda não foi publicado
+func (d *workerDeps) republish(ctx context.Context, betID string) error {
+	evc, published, err := reconcile.Decision(ctx, d.pg, betID)
+	if err != nil || published {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		return errors.New("wallet refund http " + resp.Status)
-	}
-	return nil
+	d.log.Info("republish bet_confirmed", zap.String("betId", betID), zap.String("status", evc.Status))
+	return d.publishConfirmed(ctx, evc)
 }
 
 func mustJSON(v any) []byte {
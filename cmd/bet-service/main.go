@@ -6,20 +6,25 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	kafkago "github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/cashout"
 	bhttp "github.com/radieske/sports-bet-platform-poc/internal/bet-service/http"
-	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/odds"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/outbox"
 	kpub "github.com/radieske/sports-bet-platform-poc/internal/bet-service/producer"
 	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/saga"
 	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/config"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/db"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
 )
 
 func main() {
@@ -49,8 +54,8 @@ func main() {
 	defer writer.Close()
 
 	// deps
-	repository := repo.NewPostgres(pg)
-	ov := odds.NewValidator(rdb)
+	repository := repo.NewPostgres(pg, cfg.TopicBetPlaced)
+	prices := oddscache.New(rdb, 0) // somente leitura: gravado pelo odds-processor
 
 	walletURL := os.Getenv("WALLET_URL")
 	if walletURL == "" {
@@ -59,8 +64,72 @@ func main() {
 	wcli := wallet.New(walletURL) // wallet-service
 	publ := kpub.NewKafkaPublisher(writer, cfg.TopicBetPlaced)
 
+	// Relay do outbox: publica bet_placed no Kafka (at-least-once)
+	outboxPublished := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_outbox_published_total",
+		Help: "mensagens do outbox publicadas no Kafka",
+	})
+	outboxFailed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_outbox_publish_failures_total",
+		Help: "falhas de publicação de mensagens do outbox",
+	})
+	outboxPending := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bet_outbox_pending",
+		Help: "mensagens do outbox aguardando publicação",
+	})
+	prometheus.MustRegister(outboxPublished, outboxFailed, outboxPending)
+
+	relay := outbox.NewRelay(log, repository, publ)
+	relay.OnPublished = func(n int) { outboxPublished.Add(float64(n)) }
+	relay.OnFailed = func(n int) { outboxFailed.Add(float64(n)) }
+	relay.OnPending = func(n int) { outboxPending.Set(float64(n)) }
+	go relay.Run(context.Background())
+
+	// Saga de colocação de apostas; a recuperação retoma ou compensa sagas interrompidas
+	sagaCompensated := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_placement_saga_compensated_total",
+		Help: "colocações de aposta compensadas (aposta FAILED e reserva liberada)",
+	})
+	sagaResumed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_placement_saga_resumed_total",
+		Help: "colocações de aposta retomadas pela recuperação",
+	})
+	prometheus.MustRegister(sagaCompensated, sagaResumed)
+
+	placement := &saga.Placement{
+		Log:           log,
+		Repo:          repository,
+		Wallet:        wcli,
+		Outbox:        relay,
+		OnCompensated: sagaCompensated.Inc,
+		OnResumed:     sagaResumed.Inc,
+	}
+	go placement.RunRecovery(context.Background(), 30*time.Second, 30*time.Second)
+
+	// Cash-out: oferta com as odds atuais do cache e encerramento antecipado da aposta
+	cashedOut := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_cashout_total",
+		Help: "apostas encerradas por cash-out",
+	})
+	cashoutRecovered := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_cashout_payout_recovered_total",
+		Help: "créditos de cash-out refeitos pela recuperação",
+	})
+	prometheus.MustRegister(cashedOut, cashoutRecovered)
+
+	cashouts := &cashout.Service{
+		Log:               log,
+		Repo:              repository,
+		Wallet:            wcli,
+		Prices:            prices,
+		Margin:            cashout.DefaultMargin,
+		OnCashedOut:       cashedOut.Inc,
+		OnPayoutRecovered: cashoutRecovered.Inc,
+	}
+	go cashouts.RunPayoutRecovery(context.Background(), 30*time.Second, 30*time.Second)
+
 	// HTTP público
-	api := bhttp.NewServer(log, repository, ov, wcli, publ)
+	api := bhttp.NewServer(log, repository, prices, placement, cashouts)
 	apiSrv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.HTTPPort),
 		Handler: api.Router(),
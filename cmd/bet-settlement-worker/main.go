@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	kafkago "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/consumer"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/wallet"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/config"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/db"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
+)
+
+func main() {
+	cfg := config.Load()
+	log, err := logger.New(cfg.ServiceName, cfg.Env)
+	if err != nil {
+		panic(err)
+	}
+	defer log.Sync()
+
+	pg, err := db.ConnectPostgres(cfg.PostgresDSN)
+	if err != nil {
+		log.Fatal("pg connect", zap.Error(err))
+	}
+	defer pg.Close()
+
+	// Dialer do Kafka com timeouts e identificação do cliente.
+	kDialer := &kafkago.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+		ClientID:  cfg.ServiceName,
+	}
+
+	// Consumer do tópico match_results; o commit de offset é manual (após liquidar).
+	reader := kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:     strings.Split(cfg.KafkaBrokers, ","),
+		GroupID:     "bet-settlement",
+		Topic:       cfg.TopicMatchResults,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     500 * time.Millisecond,
+		StartOffset: kafkago.FirstOffset,
+		Dialer:      kDialer,
+	})
+	defer reader.Close()
+
+	// Métricas Prometheus de consumo, liquidação e erros.
+	consumed := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bet_settlement_results_consumed_total",
+		Help: "resultados de partidas consumidos",
+	})
+	settled := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bet_settlement_bets_settled_total",
+		Help: "apostas liquidadas por resultado",
+	}, []string{"outcome"})
+	errorsBy := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bet_settlement_errors_total",
+		Help: "erros por estágio",
+	}, []string{"stage"})
+	unsettled := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bet_settlement_unsettled_results",
+		Help: "resultados gravados com apostas ainda não liquidadas (última varredura)",
+	})
+	prometheus.MustRegister(consumed, settled, errorsBy, unsettled)
+
+	settler := &consumer.Settler{
+		Log:    log,
+		Reader: reader,
+		Repo:   repo.NewPostgres(pg),
+		Wallet: wallet.New(cfg.WalletBaseURL),
+
+		OnConsumed: func() { consumed.Inc() },
+		OnSettled:  func(outcome string) { settled.WithLabelValues(outcome).Inc() },
+		OnSwept:    func(n int) { unsettled.Set(float64(n)) },
+		OnError:    func(stage string) { errorsBy.WithLabelValues(stage).Inc() },
+	}
+
+	// Servidor HTTP para métricas e health.
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), 500*time.Millisecond)
+			defer cancel()
+			if err := pg.PingContext(ctx); err != nil {
+				http.Error(w, "pg", http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("ok"))
+		})
+		addr := fmt.Sprintf(":%s", cfg.MetricsPort)
+		log.Info("metrics/health listening", zap.String("addr", addr))
+		_ = http.ListenAndServe(addr, mux)
+	}()
+
+	// Contexto para encerramento gracioso por sinais de sistema.
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Varredura de resultados gravados cuja liquidação não terminou no consumer.
+	go settler.RunSweep(ctx, cfg.SettlementSweepInterval, cfg.SettlementSweepGrace)
+
+	log.Info("bet-settlement-worker started", zap.String("consume", cfg.TopicMatchResults))
+	if err := settler.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal("settler stopped with error", zap.Error(err))
+	}
+	log.Info("bet-settlement-worker stopped")
+}
@@ -40,11 +40,20 @@ func main() {
 	)
 	defer pub.Close()
 
+	// Kafka Publisher para placares/resultados de partidas
+	resultsPub := publisher.NewKafkaPublisher(
+		strings.Split(cfg.KafkaBrokers, ","),
+		cfg.TopicMatchResults,
+		log,
+	)
+	defer resultsPub.Close()
+
 	// WS Client
 	wsClient := &service.WSClient{
-		URL:       cfg.SupplierWSURL,
-		Log:       log,
-		Publisher: pub,
+		URL:             cfg.SupplierWSURL,
+		Log:             log,
+		Publisher:       pub,
+		ResultPublisher: resultsPub,
 	}
 	go wsClient.Start(ctx)
 
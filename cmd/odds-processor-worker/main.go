@@ -16,14 +16,15 @@ import (
 
 	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 
-	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/cache"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/consumer"
-	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/pubsub"
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/pricing"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/repository"
 	sharedcache "github.com/radieske/sports-bet-platform-poc/internal/shared/cache"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/config"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/db"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddsstream"
 )
 
 func main() {
@@ -50,9 +51,25 @@ func main() {
 
 	// Instâncias de cache e repositório para o processamento de odds.
 	ttl := 60 * time.Second
-	rcache := cache.NewRedisCache(redisClient, ttl)
+	rcache := oddscache.New(redisClient, ttl)
 	repo := repository.NewPostgresRepo(pg)
 
+	// Margem da casa: padrão + regras por modalidade/competição/mercado
+	margin, err := pricing.ParseMargin(cfg.OddsMargin)
+	if err != nil {
+		log.Fatal("invalid ODDS_MARGIN", zap.Error(err))
+	}
+	rules, err := pricing.ParseRules(cfg.OddsMarginRules, cfg.OddsMarginMethod)
+	if err != nil {
+		log.Fatal("invalid ODDS_MARGIN_RULES", zap.Error(err))
+	}
+	pricer, err := pricing.New(margin, cfg.OddsMarginMethod, rules)
+	if err != nil {
+		log.Fatal("invalid odds margin config", zap.Error(err))
+	}
+	log.Info("odds pricing configured",
+		zap.Float64("margin", margin), zap.String("method", cfg.OddsMarginMethod), zap.Int("rules", len(rules)))
+
 	// Configuração do dialer do Kafka com timeouts e suporte IPv4/IPv6.
 	kDialer := &kafka.Dialer{
 		Timeout:   10 * time.Second,
@@ -88,14 +105,23 @@ func main() {
 		Name: "odds_proc_db_writes_total",
 		Help: "escritas no banco (upsert+history)",
 	})
+	dropped := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "odds_proc_dropped_total",
+		Help: "atualizações descartadas por versão (stale = fora de ordem, duplicate = reentrega)",
+	}, []string{"reason"})
+	overround := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "odds_proc_supplier_overround",
+		Help: "overround da última atualização do fornecedor por mercado (1.05 = 5% de margem)",
+	}, []string{"market"})
 	errorsBy := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "odds_proc_errors_total",
 		Help: "erros por estágio",
 	}, []string{"stage"})
-	prometheus.MustRegister(consumed, cached, persist, errorsBy)
+	prometheus.MustRegister(consumed, cached, persist, dropped, overround, errorsBy)
 
-	// Broadcaster para enviar atualizações via Redis Pub/Sub ao serviço de WebSocket.
-	broadcaster := pubsub.NewRedisBroadcaster(redisClient)
+	// Stream de odds: numera cada atualização por evento, guarda os frames recentes
+	// para replay e publica no Redis Pub/Sub para o serviço de WebSocket.
+	stream := oddsstream.New(redisClient, cfg.OddsStreamMaxLen, cfg.OddsStreamTTL)
 
 	// Processador que coordena leitura do Kafka, cache, persistência e broadcast.
 	proc := &consumer.Processor{
@@ -103,21 +129,23 @@ func main() {
 		Reader: reader,
 		Repo:   repo,
 		Cache:  rcache,
+		Pricer: pricer,
 
 		OnConsumed: func() { consumed.Inc() },
 		OnCached:   func() { cached.Inc() },
 		OnPersist:  func() { persist.Inc() },
+		OnDropped:  func(reason string) { dropped.WithLabelValues(reason).Inc() },
+		OnPriced:   func(market string, v float64) { overround.WithLabelValues(market).Set(v) },
 		OnError:    func(stage string) { errorsBy.WithLabelValues(stage).Inc() },
 
-		// Publicação de atualização no canal do WebSocket após persistência bem-sucedida.
+		// Publicação de atualização no stream/canal do WebSocket após persistência bem-sucedida.
 		OnAfterPersist: func(ev events.OddsUpdate) {
-			msg := pubsub.WSUpdate{EventID: ev.EventID, Payload: ev}
-			b, _ := json.Marshal(msg)
+			b, _ := json.Marshal(ev)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 			defer cancel()
 
-			if err := broadcaster.Publish(ctx, pubsub.ChannelOddsBroadcast, b); err != nil {
+			if _, err := stream.Publish(ctx, ev.EventID, b); err != nil {
 				log.Warn("ws broadcast publish failed", zap.Error(err))
 			}
 		},
@@ -12,10 +12,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
-	svcCache "github.com/radieske/sports-bet-platform-poc/internal/odds-service/cache"
 	httpapi "github.com/radieske/sports-bet-platform-poc/internal/odds-service/http"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/repo"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/ws"
@@ -25,6 +25,8 @@ import (
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/db"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/kafka"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddsstream"
 )
 
 func main() {
@@ -104,16 +106,65 @@ func main() {
 	// Servidor principal (REST + WS)
 	// Repositório de leitura e cache de odds
 	readRepo := &repo.ReadRepo{DB: pg}
-	oddsCache := svcCache.New(redisClient)
+	oddsCache := oddscache.New(redisClient, 0) // somente leitura
 	api := &httpapi.API{ReadRepo: readRepo, Cache: oddsCache}
 
 	// Hub WebSocket e inscrição no Redis Pub/Sub para broadcast de odds
-	hub := ws.NewHub(func(r *http.Request) bool { return true })
+	// (o snapshot enviado na inscrição usa o cache e, na falta dele, o banco)
+	snapshots := &ws.Snapshots{Cache: oddsCache, ReadRepo: readRepo}
+	// (o replay por sequência usa o stream de odds gravado pelo odds-processor)
+	stream := oddsstream.New(redisClient, 0, 0) // somente leitura
+	hub := ws.NewHub(func(r *http.Request) bool { return true }, snapshots, stream)
+	hub.SendQueueSize = cfg.WSSendQueueSize
+	hub.WriteTimeout = cfg.WSWriteTimeout
+	hub.SlowConsumerPolicy, err = ws.ParseSlowConsumerPolicy(cfg.WSSlowConsumerPolicy)
+	if err != nil {
+		log.Fatal("invalid WS_SLOW_CONSUMER_POLICY", zap.Error(err))
+	}
+	hub.PingInterval = cfg.WSPingInterval
+	hub.PongTimeout = cfg.WSPongTimeout
+	hub.IdleTimeout = cfg.WSIdleTimeout
+	hub.MaxSubscriptions = cfg.WSMaxSubscriptions
+
+	// Métricas das conexões, filas de envio e clientes lentos
+	evicted := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "odds_ws_evictions_total",
+		Help: "conexões WebSocket e SSE encerradas por cliente lento (queue_full | write_timeout)",
+	}, []string{"reason"})
+	dropped := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "odds_ws_dropped_messages_total",
+		Help: "deltas descartados por fila cheia (policy drop)",
+	})
+	queueDepth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "odds_ws_send_queue_depth",
+		Help: "frames aguardando envio somados entre as conexões",
+	}, func() float64 { total, _ := hub.QueueStats(); return float64(total) })
+	queueMax := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "odds_ws_send_queue_max_depth",
+		Help: "maior fila de envio entre as conexões",
+	}, func() float64 { _, max := hub.QueueStats(); return float64(max) })
+	connections := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "odds_ws_connections",
+		Help: "conexões WebSocket abertas",
+	}, func() float64 { n, _ := hub.Connections(); return float64(n) })
+	sseConnections := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "odds_sse_connections",
+		Help: "conexões Server-Sent Events abertas",
+	}, func() float64 { _, n := hub.Connections(); return float64(n) })
+	disconnects := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "odds_ws_disconnects_total",
+		Help: "conexões WebSocket e SSE encerradas por motivo (client_closed | pong_timeout | idle | queue_full | write_timeout | read_error | write_error)",
+	}, []string{"reason"})
+	prometheus.MustRegister(evicted, dropped, queueDepth, queueMax, connections, sseConnections, disconnects)
+	hub.OnEvicted = func(reason string) { evicted.WithLabelValues(reason).Inc() }
+	hub.OnDropped = func() { dropped.Inc() }
+	hub.OnDisconnect = func(reason string) { disconnects.WithLabelValues(reason).Inc() }
 	ws.StartRedisSubscriber(ctx, redisClient, hub)
 
 	appMux := http.NewServeMux()
-	appMux.Handle("/", api.Router())            // REST: endpoints de consulta de odds
-	appMux.HandleFunc("/ws/odds", hub.HandleWS) // WS: protocolo subscribe/unsubscribe
+	appMux.Handle("/", api.Router())                    // REST: endpoints de consulta de odds
+	appMux.HandleFunc("/ws/odds", hub.HandleWS)         // WS: protocolo subscribe/unsubscribe
+	appMux.HandleFunc("/v1/stream/odds", hub.HandleSSE) // SSE: atualizações de um evento
 
 	appSrv := &http.Server{
 		Addr:    ":" + cfg.HTTPPort,
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,9 +17,9 @@ import (
 
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/config"
 	"github.com/radieske/sports-bet-platform-poc/internal/shared/logger"
-	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 
 	sdto "github.com/radieske/sports-bet-platform-poc/internal/supplier-simulator/dto"
+	"github.com/radieske/sports-bet-platform-poc/internal/supplier-simulator/match"
 )
 
 var (
@@ -27,12 +29,16 @@ var (
 		CheckOrigin:     func(r *http.Request) bool { return true },
 	}
 
-	// Catálogo fixo de partidas simuladas para geração de odds
-	eventCatalog = []events.OddsUpdate{
-		{EventID: "MATCH_001", HomeTeam: "Flamengo", AwayTeam: "Palmeiras", Market: "1x2"},
-		{EventID: "MATCH_002", HomeTeam: "Grêmio", AwayTeam: "Internacional", Market: "1x2"},
-		{EventID: "MATCH_003", HomeTeam: "Corinthians", AwayTeam: "Santos", Market: "1x2"},
-		{EventID: "MATCH_004", HomeTeam: "São Paulo", AwayTeam: "Vasco", Market: "1x2"},
+	// Confrontos usados para agendar as partidas simuladas (do pré-jogo ao apito final)
+	fixtures = []match.Fixture{
+		{HomeTeam: "Flamengo", AwayTeam: "Palmeiras", Competition: "brasileirao-serie-a"},
+		{HomeTeam: "Grêmio", AwayTeam: "Internacional", Competition: "gauchao"},
+		{HomeTeam: "Corinthians", AwayTeam: "Santos", Competition: "paulistao"},
+		{HomeTeam: "São Paulo", AwayTeam: "Vasco", Competition: "brasileirao-serie-a"},
+		{HomeTeam: "Atlético-MG", AwayTeam: "Cruzeiro", Competition: "campeonato-mineiro"},
+		{HomeTeam: "Botafogo", AwayTeam: "Fluminense", Competition: "carioca"},
+		{HomeTeam: "Bahia", AwayTeam: "Vitória", Competition: "baianao"},
+		{HomeTeam: "Athletico-PR", AwayTeam: "Coritiba", Competition: "paranaense"},
 	}
 
 	// Métricas Prometheus para monitoramento de conexões e mensagens
@@ -44,6 +50,10 @@ var (
 		Name: "supplier_ws_messages_sent_total",
 		Help: "Total de mensagens WS enviadas",
 	})
+	matchResultsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "supplier_match_results_sent_total",
+		Help: "Mensagens de placar/resultado emitidas por status",
+	}, []string{"status"})
 )
 
 // Representa uma conexão de cliente WebSocket
@@ -106,12 +116,60 @@ func (h *hub) broadcast(v any) {
 	}
 }
 
+// maxDecisions limita quantas decisões de confirmação ficam em memória para consulta
+const maxDecisions = 10000
+
 // Server estrutura principal do serviço
 type server struct {
-	log *zap.Logger
+	log    *zap.Logger
+	engine *match.Engine
+
+	// decisões já tomadas por betId: reenvios recebem a mesma resposta e podem ser consultados
+	mu        sync.Mutex
+	decisions map[string]sdto.ConfirmResp
+	order     []string
+}
+
+func newServer(log *zap.Logger, engine *match.Engine) *server {
+	return &server{log: log, engine: engine, decisions: make(map[string]sdto.ConfirmResp)}
 }
 
-func newServer(log *zap.Logger) *server { return &server{log: log} }
+// decision retorna a decisão registrada para a aposta, se houver
+func (s *server) decision(betID string) (sdto.ConfirmResp, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.decisions[betID]
+	return d, ok
+}
+
+// remember registra a decisão descartando as mais antigas acima de maxDecisions
+func (s *server) remember(betID string, d sdto.ConfirmResp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.decisions[betID]; !ok {
+		s.order = append(s.order, betID)
+	}
+	s.decisions[betID] = d
+	for len(s.order) > maxDecisions {
+		delete(s.decisions, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Handler para consultar a decisão de uma aposta já submetida (GET /supplier/bets/{betId})
+func (s *server) betHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	d, ok := s.decision(strings.TrimPrefix(r.URL.Path, "/supplier/bets/"))
+	if !ok {
+		http.Error(w, "unknown bet", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(d)
+}
 
 // Handler para confirmar aposta (mock)
 func (s *server) confirmHandler(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +181,13 @@ func (s *server) confirmHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reenvio da mesma aposta: devolve a decisão original
+	if d, found := s.decision(req.BetID); found {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d)
+		return
+	}
+
 	ok := rand.Intn(100) < 80 // 80% sucesso
 
 	resp := sdto.ConfirmResp{
@@ -134,6 +199,16 @@ func (s *server) confirmHandler(w http.ResponseWriter, r *http.Request) {
 		resp.Reason = "supplier_reject_mock"
 	}
 
+	// O bilhete é confirmado por inteiro: qualquer seleção em evento encerrado rejeita todas
+	for _, l := range req.Legs {
+		if !s.engine.IsOpen(l.EventID) {
+			resp.Status = sdto.StatusRejected
+			resp.Reason = "event_closed:" + l.EventID
+			break
+		}
+	}
+	s.remember(req.BetID, resp)
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
@@ -146,9 +221,14 @@ func safePrefix(s string, n int) string {
 	return s[:n]
 }
 
-// gera número aleatório entre min e max
-func rnd(min, max float64) float64 {
-	return (rand.Float64() * (max - min)) + min
+// envDuration lê uma duração do ambiente (ex: "90s", "3m") com valor padrão
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
 }
 
 func main() {
@@ -160,38 +240,44 @@ func main() {
 	defer log.Sync()
 	rand.Seed(time.Now().UnixNano())
 
-	prometheus.MustRegister(wsConnections, wsMessagesSent)
+	prometheus.MustRegister(wsConnections, wsMessagesSent, matchResultsSent)
 
 	h := newHub(log)
-	s := newServer(log)
 
-	// Gera e envia odds simuladas para todos os clientes conectados a cada 3 segundos
+	// Motor de partidas: pré-jogo, kickoff, jogo ao vivo e apito final
+	engine := match.NewEngine(match.Config{
+		Slots:    4,
+		PreMatch: envDuration("SIM_PREMATCH_DURATION", 60*time.Second),
+		Live:     envDuration("SIM_MATCH_DURATION", 3*time.Minute),
+		Stagger:  envDuration("SIM_MATCH_STAGGER", 45*time.Second),
+	}, cfg.ServiceName, fixtures, time.Now())
+
+	s := newServer(log, engine)
+
+	// Gera e envia odds e placares/resultados para todos os clientes conectados a cada 3 segundos
 	go func() {
 		ticker := time.NewTicker(3 * time.Second)
 		defer ticker.Stop()
-		version := 1
-		for range ticker.C {
-			updates := make([]events.OddsUpdate, len(eventCatalog))
-			for i := range eventCatalog {
-				u := eventCatalog[i]
-				u.Odds = events.Odds{
-					Home: rnd(1.40, 3.50),
-					Draw: rnd(2.50, 4.50),
-					Away: rnd(2.00, 5.00),
+		for now := range ticker.C {
+			updates, results := engine.Tick(now)
+			for _, res := range results {
+				h.broadcast(res)
+				matchResultsSent.WithLabelValues(res.Status).Inc()
+				if res.IsFinal() {
+					log.Info("match finished",
+						zap.String("event_id", res.EventID),
+						zap.Int("home_score", res.HomeScore),
+						zap.Int("away_score", res.AwayScore),
+					)
 				}
-				u.UpdatedAt = time.Now().UTC()
-				u.Source = cfg.ServiceName
-				u.Version = version
-				updates[i] = u
 			}
-			version++
 			for _, up := range updates {
 				h.broadcast(up)
 			}
 		}
 	}()
 
-	// ==== MUX PÚBLICO (HTTP principal): /ws e /supplier/confirm
+	// ==== MUX PÚBLICO (HTTP principal): /ws, /supplier/confirm e /supplier/bets/{betId}
 	appMux := http.NewServeMux()
 
 	appMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
@@ -221,6 +307,7 @@ func main() {
 	})
 
 	appMux.HandleFunc("/supplier/confirm", s.confirmHandler)
+	appMux.HandleFunc("/supplier/bets/", s.betHandler)
 
 	// ==== MUX DE MÉTRICAS (/healthz, /metrics)
 	metricsMux := http.NewServeMux()
@@ -246,7 +333,7 @@ func main() {
 	publicAddr := fmt.Sprintf(":%s", cfg.HTTPPort)
 	log.Info("supplier simulator (public) running",
 		zap.String("addr", publicAddr),
-		zap.String("paths", "/ws,/supplier/confirm"),
+		zap.String("paths", "/ws,/supplier/confirm,/supplier/bets/{betId}"),
 	)
 	if err := http.ListenAndServe(publicAddr, appMux); err != nil {
 		log.Fatal("public server error", zap.Error(err))
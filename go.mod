@@ -3,6 +3,7 @@ module github.com/radieske/sports-bet-platform-poc
 go 1.23.3
 
 require (
+	github.com/gorilla/websocket v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/prometheus/client_golang v1.23.2
 	github.com/redis/go-redis/v9 v9.16.0
@@ -10,7 +11,6 @@ require (
 )
 
 require (
-	github.com/gorilla/websocket v1.5.1 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
 	golang.org/x/net v0.43.0 // indirect
@@ -0,0 +1,172 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-confirmation/supplier"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+	ev "github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Ações tomadas pela reconciliação de apostas presas (label de métrica e prefixo do reason)
+const (
+	ActionRequeried   = "requeried"
+	ActionResubmitted = "resubmitted"
+	ActionRejected    = "rejected"
+)
+
+// StuckBet é uma aposta em PENDING_CONFIRMATION há mais tempo que o esperado
+type StuckBet struct {
+	Placed    ev.BetPlaced
+	CreatedAt time.Time
+}
+
+// ApplyFunc aplica a decisão do fornecedor à aposta (transição de status, carteira e
+// publicação de bet_confirmed), como no consumo normal de bet_placed.
+// Retorna false se a aposta já havia saído de PENDING_CONFIRMATION.
+type ApplyFunc func(ctx context.Context, placed *ev.BetPlaced, resp *supplier.ConfirmResp) (bool, error)
+
+// Stuck encontra apostas presas em PENDING_CONFIRMATION (worker caiu, fornecedor fora
+// do ar além das tentativas ou mensagem na DLQ) e as resolve: consulta a decisão no
+// fornecedor, reenvia se ele não conhece a aposta e, em último caso (idade acima de
+// RejectAfter), rejeita estornando a reserva de saldo
+type Stuck struct {
+	Log      *zap.Logger
+	DB       *sql.DB
+	Supplier *supplier.Client
+	Apply    ApplyFunc
+
+	Interval    time.Duration // intervalo entre varreduras
+	Age         time.Duration // idade mínima para a aposta ser considerada presa
+	RejectAfter time.Duration // idade a partir da qual a aposta é rejeitada se não houver decisão
+
+	OnFound    func(n int)         // métrica (gauge)
+	OnResolved func(action string) // métrica por ação
+	OnError    func()              // métrica (counter++)
+}
+
+// Run executa a varredura periodicamente até o contexto ser cancelado
+func (s *Stuck) Run(ctx context.Context) {
+	t := time.NewTicker(s.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := s.RunOnce(ctx); err != nil {
+				s.Log.Warn("stuck bets check", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunOnce lista as apostas presas e tenta resolver cada uma
+func (s *Stuck) RunOnce(ctx context.Context) error {
+	bets, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	if s.OnFound != nil {
+		s.OnFound(len(bets))
+	}
+
+	for _, b := range bets {
+		action, err := s.resolve(ctx, b)
+		if err != nil {
+			s.Log.Warn("stuck bet not resolved", zap.String("betId", b.Placed.BetID), zap.Error(err))
+			if s.OnError != nil {
+				s.OnError()
+			}
+			continue
+		}
+		if action == "" {
+			continue
+		}
+		s.Log.Info("stuck bet resolved", zap.String("betId", b.Placed.BetID), zap.String("action", action))
+		if s.OnResolved != nil {
+			s.OnResolved(action)
+		}
+	}
+	return nil
+}
+
+// resolve retorna a ação aplicada ("" se a aposta já havia sido resolvida por outro fluxo)
+func (s *Stuck) resolve(ctx context.Context, b StuckBet) (string, error) {
+	action := ActionRequeried
+	resp, err := s.Supplier.Query(ctx, b.Placed.BetID)
+	if errors.Is(err, supplier.ErrUnknownBet) {
+		action = ActionResubmitted
+		resp, err = s.Supplier.Confirm(ctx, &b.Placed)
+	}
+	if err != nil {
+		if time.Since(b.CreatedAt) < s.RejectAfter {
+			return "", err
+		}
+		// último recurso: sem resposta do fornecedor, rejeita e estorna a reserva
+		s.Log.Warn("stuck bet timed out", zap.String("betId", b.Placed.BetID), zap.Error(err))
+		action = ActionRejected
+		resp = &supplier.ConfirmResp{Status: betstate.Rejected, Reason: "confirmation_timeout"}
+	}
+
+	// o reason em bet_transactions registra a ação da reconciliação
+	r := *resp
+	r.Reason = "stuck_" + action
+	if resp.Reason != "" {
+		r.Reason += ":" + resp.Reason
+	}
+	ok, err := s.Apply(ctx, &b.Placed, &r)
+	if err != nil || !ok {
+		return "", err
+	}
+	return action, nil
+}
+
+// List retorna as apostas em PENDING_CONFIRMATION há mais de Age cuja colocação foi
+// concluída (saga COMPLETED ou apostas anteriores à saga), com o payload de bet_placed
+func (s *Stuck) List(ctx context.Context) ([]StuckBet, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT b.id, b.user_id, COALESCE(b.event_id,''), COALESCE(b.market,''), COALESCE(b.selection,''),
+		       b.stake_cents, b.odd_value, b.created_at, o.payload
+		FROM bets b
+		LEFT JOIN bet_placement_sagas sg ON sg.bet_id = b.id
+		LEFT JOIN bet_outbox o ON o.bet_id = b.id
+		WHERE b.status = 'PENDING_CONFIRMATION'
+		  AND (sg.bet_id IS NULL OR sg.status = 'COMPLETED')
+		  AND b.created_at < NOW() - $1::interval
+		ORDER BY b.created_at
+		LIMIT 200`, fmt.Sprintf("%d seconds", int(s.Age.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []StuckBet
+	for rows.Next() {
+		var b StuckBet
+		var payload []byte
+		p := &b.Placed
+		if err := rows.Scan(&p.BetID, &p.UserID, &p.EventID, &p.Market, &p.Selection,
+			&p.StakeCents, &p.OddValue, &b.CreatedAt, &payload); err != nil {
+			return nil, err
+		}
+		// o payload do outbox traz o bilhete completo (seleções, tipo e reservedRef)
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, p); err != nil {
+				return nil, err
+			}
+		}
+		if p.ReservedRef == "" {
+			p.ReservedRef = p.BetID
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,106 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-confirmation/wallet"
+)
+
+// UncommittedBet representa uma aposta aceita cuja reserva de saldo ainda está PENDING
+type UncommittedBet struct {
+	BetID       string
+	UserID      string
+	ExternalRef string
+	Status      string
+}
+
+// Uncommitted detecta apostas confirmadas (ou já liquidadas) com reserva não efetivada
+// e tenta efetivá-las novamente; o total encontrado é reportado via callback de métrica
+type Uncommitted struct {
+	Log    *zap.Logger
+	DB     *sql.DB
+	Wallet *wallet.Client
+
+	Interval time.Duration // intervalo entre varreduras
+	Grace    time.Duration // idade mínima da aposta para ser considerada pendente
+
+	OnFound     func(n int) // métrica (gauge)
+	OnCommitted func()      // métrica (counter++)
+	OnError     func()      // métrica (counter++)
+}
+
+// Run executa a varredura periodicamente até o contexto ser cancelado
+func (u *Uncommitted) Run(ctx context.Context) {
+	t := time.NewTicker(u.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := u.RunOnce(ctx); err != nil {
+				u.Log.Warn("uncommitted reservations check", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunOnce lista as apostas com reserva pendente e tenta efetivar cada uma
+func (u *Uncommitted) RunOnce(ctx context.Context) error {
+	bets, err := u.List(ctx)
+	if err != nil {
+		return err
+	}
+	if u.OnFound != nil {
+		u.OnFound(len(bets))
+	}
+
+	for _, b := range bets {
+		if err := u.Wallet.Commit(ctx, b.UserID, b.ExternalRef); err != nil {
+			u.Log.Warn("uncommitted reservation retry failed",
+				zap.String("betId", b.BetID), zap.String("status", b.Status), zap.Error(err))
+			if u.OnError != nil {
+				u.OnError()
+			}
+			continue
+		}
+		u.Log.Info("uncommitted reservation committed", zap.String("betId", b.BetID))
+		if u.OnCommitted != nil {
+			u.OnCommitted()
+		}
+	}
+	return nil
+}
+
+// List retorna as apostas aceitas há mais de Grace cuja reserva continua PENDING
+func (u *Uncommitted) List(ctx context.Context) ([]UncommittedBet, error) {
+	rows, err := u.DB.QueryContext(ctx, `
+		SELECT b.id, b.user_id, wr.external_ref, b.status
+		FROM wallet_reservations wr
+		JOIN wallets w ON w.id = wr.wallet_id
+		JOIN bets b ON b.id::text = wr.external_ref AND b.user_id = w.user_id
+		WHERE wr.status = 'PENDING'
+		  AND b.status IN ('CONFIRMED','WON','LOST','VOID','CASHED_OUT')
+		  AND b.updated_at < NOW() - $1::interval
+		ORDER BY b.updated_at
+		LIMIT 500`, fmt.Sprintf("%d seconds", int(u.Grace.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []UncommittedBet
+	for rows.Next() {
+		var b UncommittedBet
+		if err := rows.Scan(&b.BetID, &b.UserID, &b.ExternalRef, &b.Status); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,133 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	ev "github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// PublishFunc publica bet_confirmed e marca a decisão como publicada
+type PublishFunc func(ctx context.Context, evc ev.BetConfirmed) error
+
+// Unpublished detecta apostas CONFIRMED/REJECTED cujo bet_confirmed não foi publicado
+// (worker caiu ou o Kafka falhou entre a transição e a publicação) e o republica
+type Unpublished struct {
+	Log     *zap.Logger
+	DB      *sql.DB
+	Publish PublishFunc
+
+	Interval time.Duration // intervalo entre varreduras
+	Grace    time.Duration // idade mínima da decisão para ser considerada não publicada
+
+	OnFound       func(n int) // métrica (gauge)
+	OnRepublished func()      // métrica (counter++)
+	OnError       func()      // métrica (counter++)
+}
+
+// Run executa a varredura periodicamente até o contexto ser cancelado
+func (u *Unpublished) Run(ctx context.Context) {
+	t := time.NewTicker(u.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := u.RunOnce(ctx); err != nil {
+				u.Log.Warn("unpublished decisions check", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunOnce lista as decisões não publicadas e republica cada uma
+func (u *Unpublished) RunOnce(ctx context.Context) error {
+	ids, err := u.List(ctx)
+	if err != nil {
+		return err
+	}
+	if u.OnFound != nil {
+		u.OnFound(len(ids))
+	}
+
+	for _, id := range ids {
+		evc, published, err := Decision(ctx, u.DB, id)
+		if err == nil && !published {
+			err = u.Publish(ctx, evc)
+		}
+		if err != nil {
+			u.Log.Warn("bet_confirmed republish failed", zap.String("betId", id), zap.Error(err))
+			if u.OnError != nil {
+				u.OnError()
+			}
+			continue
+		}
+		if published {
+			continue
+		}
+		u.Log.Info("bet_confirmed republished", zap.String("betId", id), zap.String("status", evc.Status))
+		if u.OnRepublished != nil {
+			u.OnRepublished()
+		}
+	}
+	return nil
+}
+
+// List retorna as apostas decididas há mais de Grace sem bet_confirmed publicado
+func (u *Unpublished) List(ctx context.Context) ([]string, error) {
+	rows, err := u.DB.QueryContext(ctx, `
+		SELECT id FROM bets
+		WHERE status IN ('CONFIRMED','REJECTED') AND confirmed_published_at IS NULL
+		  AND updated_at < NOW() - $1::interval
+		ORDER BY updated_at
+		LIMIT 500`, fmt.Sprintf("%d seconds", int(u.Grace.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// Decision remonta o bet_confirmed de uma aposta já decidida (status e reason da transição
+// registrada em bet_transactions) e indica se ele já foi publicado
+func Decision(ctx context.Context, db *sql.DB, betID string) (ev.BetConfirmed, bool, error) {
+	evc := ev.BetConfirmed{BetID: betID}
+	var reason, providerRef sql.NullString
+	var publishedAt sql.NullTime
+	if err := db.QueryRowContext(ctx, `
+		SELECT b.user_id, b.status, b.provider_ref, b.confirmed_published_at, t.reason, COALESCE(t.created_at, b.updated_at)
+		FROM bets b
+		LEFT JOIN LATERAL (
+		  SELECT reason, created_at FROM bet_transactions
+		  WHERE bet_id = b.id AND old_status = 'PENDING_CONFIRMATION'
+		  ORDER BY created_at DESC LIMIT 1
+		) t ON TRUE
+		WHERE b.id = $1`, betID,
+	).Scan(&evc.UserID, &evc.Status, &providerRef, &publishedAt, &reason, &evc.Ts); err != nil {
+		return ev.BetConfirmed{}, false, err
+	}
+	evc.Reason, evc.ProviderRef = reason.String, providerRef.String
+	return evc, publishedAt.Valid, nil
+}
+
+// MarkPublished registra a publicação do bet_confirmed da aposta
+func MarkPublished(ctx context.Context, db *sql.DB, betID string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE bets SET confirmed_published_at=NOW()
+		WHERE id=$1 AND confirmed_published_at IS NULL`, betID)
+	return err
+}
@@ -0,0 +1,89 @@
+package supplier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ev "github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// ErrUnknownBet indica que o fornecedor não tem decisão registrada para a aposta
+var ErrUnknownBet = errors.New("supplier: unknown bet")
+
+// ConfirmResp é a decisão do fornecedor sobre uma aposta
+type ConfirmResp struct {
+	Status      string `json:"status"`
+	ProviderRef string `json:"providerRef"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Client chama a API HTTP de confirmação do fornecedor (supplier-simulator)
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New cria o cliente derivando a base HTTP a partir da URL de WS do fornecedor
+func New(wsURL string) *Client {
+	base := strings.Replace(wsURL, "ws://", "http://", 1)
+	base = strings.TrimSuffix(base, "/ws")
+	return &Client{BaseURL: base, HTTP: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Confirm submete o bilhete inteiro (todas as seleções) para confirmação
+func (c *Client) Confirm(ctx context.Context, p *ev.BetPlaced) (*ConfirmResp, error) {
+	legs := make([]map[string]any, 0, len(p.SelectionLegs()))
+	for _, l := range p.SelectionLegs() {
+		legs = append(legs, map[string]any{
+			"eventId":   l.EventID,
+			"market":    l.Market,
+			"selection": l.Selection,
+			"odd_value": l.OddValue,
+		})
+	}
+	body, _ := json.Marshal(map[string]any{
+		"betId":       p.BetID,
+		"userId":      p.UserID,
+		"eventId":     p.EventID,
+		"stake_cents": p.StakeCents,
+		"odd_value":   p.OddValue,
+		"legs":        legs,
+	})
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/supplier/confirm", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req)
+}
+
+// Query consulta a decisão já tomada pelo fornecedor para a aposta
+// Retorna ErrUnknownBet se a aposta nunca chegou ao fornecedor
+func (c *Client) Query(ctx context.Context, betID string) (*ConfirmResp, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/supplier/bets/"+betID, nil)
+	return c.do(req)
+}
+
+func (c *Client) do(req *http.Request) (*ConfirmResp, error) {
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrUnknownBet
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("supplier http %s", resp.Status)
+	}
+
+	var out ConfirmResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client chama o wallet-service para efetivar ou estornar reservas de apostas
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New cria o cliente; base vazia assume o endereço do ambiente Docker
+func New(base string) *Client {
+	if base == "" {
+		base = "http://wallet-service:8082"
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(base, "/"),
+		HTTP:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Commit efetiva a reserva (idempotente no wallet-service)
+func (c *Client) Commit(ctx context.Context, userID, externalRef string) error {
+	return c.post(ctx, "/wallet/commit", map[string]any{
+		"userId":       userID,
+		"external_ref": externalRef,
+	})
+}
+
+// Refund estorna a reserva devolvendo o saldo (idempotente no wallet-service)
+func (c *Client) Refund(ctx context.Context, userID, externalRef string) error {
+	return c.post(ctx, "/wallet/refund", map[string]any{
+		"userId":       userID,
+		"external_ref": externalRef,
+	})
+}
+
+// CommitWithRetry tenta efetivar a reserva com backoff linear
+func (c *Client) CommitWithRetry(ctx context.Context, userID, externalRef string, retries int) error {
+	err := c.Commit(ctx, userID, externalRef)
+	for i := 0; err != nil && i < retries; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(300*(i+1)) * time.Millisecond):
+		}
+		err = c.Commit(ctx, userID, externalRef)
+	}
+	return err
+}
+
+func (c *Client) post(ctx context.Context, path string, payload any) error {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.New("wallet " + strings.TrimPrefix(path, "/wallet/") + " http " + resp.Status)
+	}
+	return nil
+}
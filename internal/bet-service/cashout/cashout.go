@@ -0,0 +1,205 @@
+package cashout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
+)
+
+// DefaultMargin é a margem da casa aplicada sobre o valor justo do cash-out
+const DefaultMargin = 0.05
+
+var (
+	ErrNotFound         = errors.New("bet not found")
+	ErrNotCashable      = errors.New("bet not eligible for cash-out")
+	ErrPriceUnavailable = errors.New("current price unavailable")
+	ErrPriceChanged     = errors.New("price changed")
+	// ErrPayoutPending indica aposta já encerrada (CASHED_OUT) cujo crédito falhou;
+	// a varredura de RunPayoutRecovery refaz o crédito
+	ErrPayoutPending = errors.New("cash-out payout pending")
+)
+
+// Offer é a oferta de cash-out calculada com as odds atuais
+type Offer struct {
+	BetID       string
+	AmountCents int64
+	StakeCents  int64
+	CashedOut   bool // true quando a aposta já foi encerrada por este valor
+}
+
+// Service calcula ofertas e executa cash-out de apostas CONFIRMED.
+// O preço atual vem do cache compartilhado de odds (oddscache), gravado pelo odds-processor.
+type Service struct {
+	Log    *zap.Logger
+	Repo   *repo.Postgres
+	Wallet *wallet.Client
+	Prices *oddscache.Cache
+	Margin float64
+
+	OnCashedOut       func() // métricas
+	OnPayoutRecovered func() // métricas
+}
+
+// Quote calcula a oferta atual de cash-out da aposta
+func (s *Service) Quote(ctx context.Context, betID string) (Offer, error) {
+	b, err := s.load(ctx, betID)
+	if err != nil {
+		return Offer{}, err
+	}
+	if b.Status == repo.StatusCashedOut {
+		return Offer{BetID: b.ID, AmountCents: b.CashoutCents, StakeCents: b.StakeCents, CashedOut: true}, nil
+	}
+	return s.quote(ctx, b)
+}
+
+// Execute encerra a aposta pelo valor ofertado. expectedCents é o valor cotado pelo cliente:
+// se o preço mudou desde a cotação, retorna ErrPriceChanged com a nova oferta.
+// Reexecutar após CASHED_OUT refaz apenas o crédito (idempotente por aposta no wallet).
+// Se o crédito falha a aposta continua CASHED_OUT e Execute retorna ErrPayoutPending com a oferta.
+func (s *Service) Execute(ctx context.Context, betID string, expectedCents int64) (Offer, error) {
+	b, err := s.load(ctx, betID)
+	if err != nil {
+		return Offer{}, err
+	}
+
+	offer := Offer{BetID: b.ID, AmountCents: b.CashoutCents, StakeCents: b.StakeCents, CashedOut: true}
+	if b.Status != repo.StatusCashedOut {
+		if offer, err = s.quote(ctx, b); err != nil {
+			return Offer{}, err
+		}
+		if offer.AmountCents != expectedCents {
+			return offer, ErrPriceChanged
+		}
+
+		// A transição vem antes do crédito: garante que liquidação e cash-out não pagam a mesma aposta
+		ok, err := s.Repo.CashOut(ctx, b.ID, offer.AmountCents)
+		if err != nil {
+			return Offer{}, err
+		}
+		if !ok {
+			return Offer{}, ErrNotCashable
+		}
+		offer.CashedOut = true
+		s.Log.Info("bet cashed out", zap.String("betId", b.ID), zap.Int64("amount", offer.AmountCents))
+		if s.OnCashedOut != nil {
+			s.OnCashedOut()
+		}
+	}
+
+	if err := s.payout(ctx, b.ID, b.UserID, offer.AmountCents); err != nil {
+		// a aposta permanece CASHED_OUT; a varredura (ou um novo POST) refaz o crédito
+		s.Log.Error("cashout payout failed", zap.String("betId", b.ID), zap.Error(err))
+		return offer, ErrPayoutPending
+	}
+	return offer, nil
+}
+
+// payout credita o cash-out com external_ref bet-cashout:{betId} e marca a aposta como paga
+func (s *Service) payout(ctx context.Context, betID, userID string, amountCents int64) error {
+	if err := s.Wallet.Payout(ctx, userID, amountCents, "bet-cashout:"+betID, betID); err != nil {
+		return err
+	}
+	return s.Repo.MarkCashoutPaid(ctx, betID)
+}
+
+// RecoverPayouts refaz o crédito de apostas CASHED_OUT há mais de staleAfter sem crédito confirmado
+// (ex.: wallet-service indisponível no momento do cash-out ou bet-service reiniciado)
+func (s *Service) RecoverPayouts(ctx context.Context, staleAfter time.Duration) error {
+	bets, err := s.Repo.ListUnpaidCashouts(ctx, staleAfter, 100)
+	if err != nil {
+		return err
+	}
+	for _, b := range bets {
+		if err := s.payout(ctx, b.ID, b.UserID, b.CashoutCents); err != nil {
+			s.Log.Warn("cashout payout retry failed", zap.String("betId", b.ID), zap.Error(err))
+			continue
+		}
+		s.Log.Info("cashout payout recovered", zap.String("betId", b.ID), zap.Int64("amount", b.CashoutCents))
+		if s.OnPayoutRecovered != nil {
+			s.OnPayoutRecovered()
+		}
+	}
+	return nil
+}
+
+// RunPayoutRecovery executa RecoverPayouts na inicialização e depois periodicamente
+func (s *Service) RunPayoutRecovery(ctx context.Context, interval, staleAfter time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.RecoverPayouts(ctx, staleAfter); err != nil && ctx.Err() == nil {
+			s.Log.Warn("cashout payout recovery", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Service) load(ctx context.Context, betID string) (*repo.Bet, error) {
+	b, err := s.Repo.GetWithLegs(ctx, betID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+// quote calcula o valor justo da aposta com as odds atuais e aplica a margem:
+// stake × Π(odd da seleção ganha | odd apostada / odd atual das seleções em aberto).
+// Seleções anuladas contam como 1.0; seleção perdida torna a aposta inelegível.
+func (s *Service) quote(ctx context.Context, b *repo.Bet) (Offer, error) {
+	if b.Status != betstate.Confirmed {
+		return Offer{}, ErrNotCashable
+	}
+
+	factor := 1.0
+	for _, l := range b.Legs {
+		switch l.Status {
+		case "WON":
+			factor *= l.OddValue
+		case "VOID":
+		case "LOST":
+			return Offer{}, ErrNotCashable
+		default:
+			cur, err := s.currentOdd(ctx, l)
+			if err != nil {
+				return Offer{}, err
+			}
+			factor *= l.OddValue / cur
+		}
+	}
+
+	amount := int64(math.Floor(float64(b.StakeCents) * factor * (1 - s.Margin)))
+	if amount <= 0 {
+		return Offer{}, ErrPriceUnavailable
+	}
+	return Offer{BetID: b.ID, AmountCents: amount, StakeCents: b.StakeCents}, nil
+}
+
+// currentOdd lê a odd atual da seleção no cache compartilhado de odds;
+// mercados suspensos ou fechados não têm preço para cash-out
+func (s *Service) currentOdd(ctx context.Context, l repo.Leg) (float64, error) {
+	odd, err := s.Prices.SelectionPrice(ctx, l.EventID, l.Market, l.Selection)
+	if errors.Is(err, oddscache.ErrNotFound) || errors.Is(err, oddscache.ErrSelectionNotFound) ||
+		errors.Is(err, oddscache.ErrMarketNotOpen) {
+		return 0, ErrPriceUnavailable
+	}
+	if err != nil {
+		return 0, err
+	}
+	if odd <= 1 {
+		return 0, ErrPriceUnavailable
+	}
+	return odd, nil
+}
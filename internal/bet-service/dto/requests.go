@@ -3,8 +3,40 @@ package dto
 type PlaceBetRequest struct {
 	UserID     string  `json:"userId"`
 	EventID    string  `json:"eventId"`
-	Market     string  `json:"market"`    // ex: "MATCH_ODDS"
-	Selection  string  `json:"selection"` // "home" | "draw" | "away"
+	Market     string  `json:"market"`    // marketKey do odds-service, ex: "1x2", "over_under:2.5"
+	Selection  string  `json:"selection"` // nome da seleção, ex: "home", "over", "2-1"
 	StakeCents int64   `json:"stake_cents"`
 	OddValue   float64 `json:"odd_value"` // odd que o cliente viu
+
+	// Legs define uma aposta acumulada (2+ seleções de eventos distintos).
+	// Quando informado, eventId/market/selection/odd_value de topo são ignorados.
+	Legs []BetLegRequest `json:"legs,omitempty"`
+
+	// SystemType transforma as seleções de legs em uma aposta sistema
+	// (TRIXIE | PATENT | YANKEE | LUCKY15); stake_cents passa a ser o valor por linha.
+	SystemType string `json:"system_type,omitempty"`
+
+	// Política de aceitação caso a odd atual difira da informada
+	// (EXACT | ACCEPT_HIGHER | ACCEPT_ANY); ausente usa a política do usuário ou EXACT.
+	OddsPolicy       string  `json:"odds_policy,omitempty"`
+	OddsTolerancePct float64 `json:"odds_tolerance_pct,omitempty"` // variação aceita em %, ex: 2.5
+}
+
+// BetLegRequest representa uma seleção de um bilhete acumulado
+type BetLegRequest struct {
+	EventID   string  `json:"eventId"`
+	Market    string  `json:"market"`
+	Selection string  `json:"selection"`
+	OddValue  float64 `json:"odd_value"` // odd que o cliente viu para a seleção
+}
+
+// CashoutRequest confirma o cash-out pelo valor cotado em GET /bets/{id}/cashout
+type CashoutRequest struct {
+	AmountCents int64 `json:"amount_cents"`
+}
+
+// OddsPolicyRequest define a política padrão de aceitação de odds do usuário
+type OddsPolicyRequest struct {
+	Mode         string  `json:"mode"` // EXACT | ACCEPT_HIGHER | ACCEPT_ANY
+	TolerancePct float64 `json:"tolerance_pct"`
 }
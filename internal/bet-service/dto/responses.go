@@ -1,13 +1,96 @@
 package dto
 
+import "time"
+
 type PlaceBetResponse struct {
-	BetID      string `json:"betId"`
-	Status     string `json:"status"` // PENDING_CONFIRMATION
-	NewBalance *int64 `json:"new_balance,omitempty"`
-	Message    string `json:"message,omitempty"`
+	BetID        string  `json:"betId"`
+	Status       string  `json:"status"`    // PENDING_CONFIRMATION
+	BetType      string  `json:"bet_type"`  // SINGLE | ACCUMULATOR
+	OddValue     float64 `json:"odd_value"` // odd aceita (combinada) com que a aposta foi colocada
+	PotentialWin int64   `json:"potential_win"`
+	NewBalance   *int64  `json:"new_balance,omitempty"`
+	Message      string  `json:"message,omitempty"`
+
+	// Preço aceito por seleção segundo a política de odds
+	OddsPolicy string             `json:"odds_policy,omitempty"`
+	Legs       []AcceptedLegPrice `json:"legs,omitempty"`
+}
+
+// AcceptedLegPrice compara a odd pedida com a odd aceita de uma seleção
+type AcceptedLegPrice struct {
+	EventID      string  `json:"eventId"`
+	RequestedOdd float64 `json:"requested_odd"`
+	AcceptedOdd  float64 `json:"accepted_odd"`
+}
+
+// PlaceSystemBetResponse descreve o bilhete sistema e as apostas geradas para cada linha
+type PlaceSystemBetResponse struct {
+	SlipID          string             `json:"slipId"`
+	Status          string             `json:"status"`
+	SystemType      string             `json:"system_type"`
+	LineCount       int                `json:"line_count"`
+	UnitStakeCents  int64              `json:"unit_stake_cents"`
+	TotalStakeCents int64              `json:"total_stake_cents"`
+	Lines           []PlaceBetResponse `json:"lines"`
+	OddsPolicy      string             `json:"odds_policy,omitempty"`
+	Legs            []AcceptedLegPrice `json:"legs,omitempty"` // preço aceito por seleção
 }
 
 type BetStatusResponse struct {
 	BetID  string `json:"betId"`
 	Status string `json:"status"`
 }
+
+// BetHistoryResponse é uma página do histórico de apostas do usuário
+type BetHistoryResponse struct {
+	Bets       []BetDetailResponse `json:"bets"`
+	NextCursor string              `json:"next_cursor,omitempty"` // vazio na última página
+}
+
+type BetDetailResponse struct {
+	BetID        string                  `json:"betId"`
+	UserID       string                  `json:"userId"`
+	SlipID       string                  `json:"slipId,omitempty"`
+	BetType      string                  `json:"bet_type"`
+	Status       string                  `json:"status"`
+	StakeCents   int64                   `json:"stake_cents"`
+	OddValue     float64                 `json:"odd_value"`
+	PotentialWin int64                   `json:"potential_win"`
+	CashoutCents int64                   `json:"cashout_cents,omitempty"`
+	Legs         []BetLegResponse        `json:"legs"`
+	Transitions  []BetTransitionResponse `json:"transitions"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
+
+type BetLegResponse struct {
+	EventID   string  `json:"eventId"`
+	Market    string  `json:"market"`
+	Selection string  `json:"selection"`
+	OddValue  float64 `json:"odd_value"`
+	Status    string  `json:"status"`
+}
+
+// BetTransitionResponse é uma mudança de status registrada em bet_transactions
+type BetTransitionResponse struct {
+	OldStatus string    `json:"old_status,omitempty"`
+	NewStatus string    `json:"new_status"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CashoutResponse traz a oferta de cash-out (OFFERED) ou o valor pago (CASHED_OUT)
+type CashoutResponse struct {
+	BetID       string `json:"betId"`
+	Status      string `json:"status"` // OFFERED | CASHED_OUT
+	AmountCents int64  `json:"amount_cents"`
+	StakeCents  int64  `json:"stake_cents"`
+	Message     string `json:"message,omitempty"`
+}
+
+// OddsPolicyResponse é a política de aceitação de odds vigente para o usuário
+type OddsPolicyResponse struct {
+	UserID       string  `json:"userId"`
+	Mode         string  `json:"mode"`
+	TolerancePct float64 `json:"tolerance_pct"`
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/dto"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/slip"
+)
+
+// Cabeçalhos de idempotência do POST /bets
+const (
+	headerIdempotencyKey = "Idempotency-Key"
+	headerReplayed       = "Idempotent-Replayed"
+	maxIdempotencyKeyLen = 255
+)
+
+// withIdempotency executa place uma única vez por (userId, Idempotency-Key): retentativas com
+// o mesmo payload recebem a resposta original e payload diferente com a mesma chave é rejeitado.
+// Apenas respostas 2xx são gravadas. Falhas que não criaram aposta liberam a chave para nova
+// tentativa; se a aposta (ou bilhete) já foi criada, a chave fica presa a ela e a retentativa
+// recebe o estado atual, pois a recuperação da saga pode concluí-la depois.
+// place retorna o betID (ou slipID, em apostas sistema) criado, vazio se nada foi criado.
+func (s *Server) withIdempotency(w http.ResponseWriter, r *http.Request, key string, req dto.PlaceBetRequest, place func(http.ResponseWriter) (betID, slipID string)) {
+	if len(key) > maxIdempotencyKeyLen {
+		http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+		return
+	}
+	canonical, _ := json.Marshal(req)
+	sum := sha256.Sum256(canonical)
+	hash := hex.EncodeToString(sum[:])
+
+	prev, err := s.repo.ClaimIdempotencyKey(r.Context(), req.UserID, key, hash)
+	switch {
+	case errors.Is(err, repo.ErrIdempotencyMismatch), errors.Is(err, repo.ErrIdempotencyInFlight):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		s.log.Error("idempotency claim", zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	case prev != nil && prev.Body == nil:
+		w.Header().Set(headerReplayed, "true")
+		s.replayPlaced(w, r, prev)
+		return
+	case prev != nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(headerReplayed, "true")
+		w.WriteHeader(prev.StatusCode)
+		_, _ = w.Write(prev.Body)
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	betID, slipID := place(rec)
+
+	// grava mesmo se o cliente desconectou, para a retentativa receber a resposta original
+	ctx := context.WithoutCancel(r.Context())
+	switch {
+	case rec.status >= 200 && rec.status < 300:
+		err = s.repo.CompleteIdempotencyKey(ctx, req.UserID, key, rec.status, rec.body.Bytes())
+	case betID != "" || slipID != "":
+		err = s.repo.AttachIdempotencyBet(ctx, req.UserID, key, betID, slipID)
+	default:
+		err = s.repo.ReleaseIdempotencyKey(ctx, req.UserID, key)
+	}
+	if err != nil {
+		s.log.Error("idempotency store", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// replayPlaced responde a retentativa de uma requisição que falhou depois de criar a aposta
+// com o estado atual da aposta (ou do bilhete e suas linhas)
+func (s *Server) replayPlaced(w http.ResponseWriter, r *http.Request, prev *repo.IdempotentResponse) {
+	if prev.SlipID != "" {
+		bs, err := s.repo.GetSlip(r.Context(), prev.SlipID)
+		if err != nil {
+			s.log.Error("idempotency replay", zap.String("slipId", prev.SlipID), zap.Error(err))
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		resp := dto.PlaceSystemBetResponse{
+			SlipID:          bs.ID,
+			SystemType:      bs.SystemType,
+			LineCount:       len(bs.Lines),
+			UnitStakeCents:  bs.UnitStakeCents,
+			TotalStakeCents: bs.TotalStakeCents(),
+		}
+		for _, l := range bs.Lines {
+			resp.Lines = append(resp.Lines, dto.PlaceBetResponse{
+				BetID:        l.ID,
+				Status:       l.Status,
+				BetType:      l.BetType,
+				OddValue:     l.OddValue,
+				PotentialWin: slip.PotentialWin(l.StakeCents, l.OddValue),
+			})
+		}
+		// as linhas avançam juntas na colocação; se já divergiram (liquidação), cada uma traz o seu
+		if len(bs.Lines) > 0 {
+			resp.Status = bs.Lines[0].Status
+		}
+		for _, l := range bs.Lines {
+			if l.Status != resp.Status {
+				resp.Status = "MIXED"
+				break
+			}
+		}
+		writeJSON(w, resp)
+		return
+	}
+
+	b, err := s.repo.GetWithLegs(r.Context(), prev.BetID)
+	if err != nil {
+		s.log.Error("idempotency replay", zap.String("betId", prev.BetID), zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, dto.PlaceBetResponse{
+		BetID:        b.ID,
+		Status:       b.Status,
+		BetType:      b.BetType,
+		OddValue:     b.OddValue,
+		PotentialWin: slip.PotentialWin(b.StakeCents, b.OddValue),
+	})
+}
+
+// responseRecorder repassa a resposta ao cliente e guarda status e corpo
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -3,118 +3,403 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/cashout"
 	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/dto"
 	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/odds"
 	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
-	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet"
-	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/saga"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/slip"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
 )
 
 type Server struct {
-	log  *zap.Logger
-	repo *repo.Postgres
-	odds *odds.Validator
-	wcli *wallet.Client
-	publ interface {
-		PublishBetPlaced(context.Context, events.BetPlaced) error
-	}
+	log       *zap.Logger
+	repo      *repo.Postgres
+	prices    *oddscache.Cache
+	placement *saga.Placement
+	cashout   *cashout.Service
 }
 
-func NewServer(log *zap.Logger, r *repo.Postgres, v *odds.Validator, w *wallet.Client, p interface {
-	PublishBetPlaced(context.Context, events.BetPlaced) error
-}) *Server {
-	return &Server{log: log, repo: r, odds: v, wcli: w, publ: p}
+func NewServer(log *zap.Logger, r *repo.Postgres, prices *oddscache.Cache, p *saga.Placement, c *cashout.Service) *Server {
+	return &Server{log: log, repo: r, prices: prices, placement: p, cashout: c}
 }
 
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/bets", s.placeBet)      // POST
-	mux.HandleFunc("/bets/", s.getBetStatus) // GET /bets/{id}
+	mux.HandleFunc("/bets", s.bets)              // POST (nova aposta) | GET ?userId=... (histórico)
+	mux.HandleFunc("/users/", s.oddsPolicyRoute) // GET,PUT /users/{userId}/odds-policy
+	mux.HandleFunc("/bets/", s.betRoutes)        // GET /bets/{id} | GET,POST /bets/{id}/cashout
 	return mux
 }
 
-func (s *Server) placeBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+// bets encaminha /bets por método: POST cria aposta, GET lista o histórico
+func (s *Server) bets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.placeBet(w, r)
+	case http.MethodGet:
+		s.listBets(w, r)
+	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
 	}
+}
+
+func (s *Server) placeBet(w http.ResponseWriter, r *http.Request) {
 	var req dto.PlaceBetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "bad json", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == "" || req.EventID == "" || req.Market == "" || req.Selection == "" || req.StakeCents <= 0 || req.OddValue <= 0 {
+
+	if req.UserID == "" || req.StakeCents <= 0 {
 		http.Error(w, "invalid payload", http.StatusBadRequest)
 		return
 	}
 
-	// 1) Valida odd atual no cache
-	curOddStr, err := s.odds.CurrentOdd(r.Context(), req.EventID, req.Market, req.Selection)
-	if err == nil {
-		// compara como string simples; se quiser tolerância, parse float e compare delta
-		if curOddStr != "" {
-			// se divergir muito, retorne 409 e a odd corrente
-			if curOddStr != strconv.FormatFloat(req.OddValue, 'f', -1, 64) {
-				http.Error(w, "odd changed; current="+curOddStr, http.StatusConflict)
-				return
-			}
+	if key := r.Header.Get(headerIdempotencyKey); key != "" {
+		s.withIdempotency(w, r, key, req, func(w http.ResponseWriter) (string, string) { return s.place(w, r, req) })
+		return
+	}
+	s.place(w, r, req)
+}
+
+// place valida e coloca a aposta (simples, acumulada ou sistema)
+// Retorna o betID (ou slipID) criado, mesmo quando a resposta é de erro, e vazio se nada foi criado
+func (s *Server) place(w http.ResponseWriter, r *http.Request, req dto.PlaceBetRequest) (betID, slipID string) {
+	if req.SystemType != "" {
+		return "", s.placeSystemBet(w, r, req)
+	}
+
+	bet := toBet(req)
+	if err := slip.Validate(bet.Legs); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return "", ""
+	}
+
+	// 1) Aplica a política de odds: cada seleção passa a ter a odd atual aceita
+	policy, prices, ok := s.acceptOdds(w, r, req, bet.Legs)
+	if !ok {
+		return "", ""
+	}
+	bet.OddValue = slip.CombinedOdd(bet.Legs)
+
+	// 2) Saga de colocação: cria a aposta PENDING, reserva saldo e libera bet_placed no outbox
+	betID, err := s.placement.Place(r.Context(), bet)
+	if err != nil {
+		if errors.Is(err, saga.ErrReserveFailed) {
+			http.Error(w, "wallet reserve failed", http.StatusConflict)
+			return betID, ""
 		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return betID, ""
 	}
 
-	// 2) Cria aposta local PENDING
-	betID, err := s.repo.CreatePending(r.Context(), &repo.Bet{
-		UserID:     req.UserID,
-		EventID:    req.EventID,
-		Market:     req.Market,
-		Selection:  req.Selection,
-		StakeCents: req.StakeCents,
-		OddValue:   req.OddValue,
+	writeJSON(w, dto.PlaceBetResponse{
+		BetID:        betID,
+		Status:       "PENDING_CONFIRMATION",
+		BetType:      bet.BetType,
+		OddValue:     bet.OddValue,
+		PotentialWin: slip.PotentialWin(bet.StakeCents, bet.OddValue),
+		OddsPolicy:   policy.Mode,
+		Legs:         prices,
 	})
+	return betID, ""
+}
+
+// placeSystemBet expande a aposta sistema em linhas (cada uma uma aposta própria sob o
+// mesmo bilhete) e reserva o total: valor unitário × quantidade de linhas.
+// Retorna o slipID criado (vazio se nada foi criado)
+func (s *Server) placeSystemBet(w http.ResponseWriter, r *http.Request, req dto.PlaceBetRequest) string {
+	legs := make([]repo.Leg, 0, len(req.Legs))
+	for _, l := range req.Legs {
+		legs = append(legs, repo.Leg{EventID: l.EventID, Market: l.Market, Selection: l.Selection, OddValue: l.OddValue})
+	}
+	if err := slip.ValidateSystem(req.SystemType, legs); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return ""
+	}
+	// as linhas são geradas com as odds aceitas pela política
+	policy, prices, ok := s.acceptOdds(w, r, req, legs)
+	if !ok {
+		return ""
+	}
+	combos, err := slip.ExpandSystem(req.SystemType, legs)
 	if err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return ""
+	}
+
+	bs := &repo.Slip{UserID: req.UserID, SystemType: strings.ToUpper(req.SystemType), UnitStakeCents: req.StakeCents}
+	for _, c := range combos {
+		line := &repo.Bet{UserID: req.UserID, BetType: repo.BetTypeAccumulator, StakeCents: req.StakeCents, Legs: c}
+		if len(c) == 1 {
+			line.BetType = repo.BetTypeSingle
+			line.EventID, line.Market, line.Selection = c[0].EventID, c[0].Market, c[0].Selection
+		}
+		line.OddValue = slip.CombinedOdd(c)
+		bs.Lines = append(bs.Lines, line)
+	}
+
+	slipID, betIDs, err := s.placement.PlaceSlip(r.Context(), bs)
+	if err != nil {
+		if errors.Is(err, saga.ErrReserveFailed) {
+			http.Error(w, "wallet reserve failed", http.StatusConflict)
+			return slipID
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return slipID
 	}
 
-	// 3) Reserva saldo via wallet (external_ref = betID)
-	if _, err := s.wcli.Reserve(r.Context(), req.UserID, req.StakeCents, betID); err != nil {
-		http.Error(w, "wallet reserve failed", http.StatusConflict)
-		return
+	resp := dto.PlaceSystemBetResponse{
+		SlipID:          slipID,
+		Status:          "PENDING_CONFIRMATION",
+		SystemType:      bs.SystemType,
+		LineCount:       len(bs.Lines),
+		UnitStakeCents:  bs.UnitStakeCents,
+		TotalStakeCents: bs.TotalStakeCents(),
+		OddsPolicy:      policy.Mode,
+		Legs:            prices,
+	}
+	for i, l := range bs.Lines {
+		resp.Lines = append(resp.Lines, dto.PlaceBetResponse{
+			BetID:        betIDs[i],
+			Status:       "PENDING_CONFIRMATION",
+			BetType:      l.BetType,
+			OddValue:     l.OddValue,
+			PotentialWin: slip.PotentialWin(l.StakeCents, l.OddValue),
+		})
+	}
+	writeJSON(w, resp)
+	return slipID
+}
+
+// acceptOdds compara a odd informada de cada seleção com a odd atual do cache segundo a
+// política de odds (do request, do usuário ou EXACT). Seleções aceitas passam a usar a odd
+// atual; se alguma não for aceita responde 409 com a odd corrente e retorna ok=false.
+// Sem preço em cache a aposta é recusada (409): nunca aceitamos a odd informada às cegas.
+// Mercados fora de OPEN são recusados com 409 e código market_{status} (ex.: market_suspended)
+func (s *Server) acceptOdds(w http.ResponseWriter, r *http.Request, req dto.PlaceBetRequest, legs []repo.Leg) (odds.Policy, []dto.AcceptedLegPrice, bool) {
+	policy, err := s.oddsPolicy(r.Context(), req)
+	if err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return odds.Policy{}, nil, false
 	}
 
-	// 4) Publica evento bet_placed
-	_ = s.publ.PublishBetPlaced(r.Context(), events.BetPlaced{
-		BetID:       betID,
-		UserID:      req.UserID,
-		EventID:     req.EventID,
-		Market:      req.Market,
-		Selection:   req.Selection,
-		StakeCents:  req.StakeCents,
-		OddValue:    req.OddValue,
-		ReservedRef: betID,
-	})
+	prices := make([]dto.AcceptedLegPrice, 0, len(legs))
+	for i, l := range legs {
+		m, err := s.prices.Market(r.Context(), l.EventID, l.Market)
+		switch {
+		case errors.Is(err, oddscache.ErrNotFound):
+			http.Error(w, "odds unavailable; eventId="+l.EventID, http.StatusConflict)
+			return odds.Policy{}, nil, false
+		case err != nil:
+			s.log.Error("read current odds", zap.String("eventId", l.EventID), zap.Error(err))
+			http.Error(w, "odds unavailable", http.StatusServiceUnavailable)
+			return odds.Policy{}, nil, false
+		case !m.IsOpen():
+			http.Error(w, "market_"+strings.ToLower(m.Status)+"; eventId="+l.EventID+"; market="+l.Market, http.StatusConflict)
+			return odds.Policy{}, nil, false
+		}
 
-	writeJSON(w, dto.PlaceBetResponse{
-		BetID:  betID,
-		Status: "PENDING_CONFIRMATION",
-	})
+		cur, found := m.Price(l.Selection)
+		if !found {
+			http.Error(w, "invalid payload: selection not offered; eventId="+l.EventID, http.StatusBadRequest)
+			return odds.Policy{}, nil, false
+		}
+		accepted, ok := policy.Accept(l.OddValue, cur)
+		if !ok {
+			http.Error(w, "odd changed; eventId="+l.EventID+"; current="+strconv.FormatFloat(cur, 'f', -1, 64), http.StatusConflict)
+			return odds.Policy{}, nil, false
+		}
+		legs[i].OddValue = accepted
+		prices = append(prices, dto.AcceptedLegPrice{EventID: l.EventID, RequestedOdd: l.OddValue, AcceptedOdd: accepted})
+	}
+	return policy, prices, true
 }
 
-func (s *Server) getBetStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// oddsPolicy resolve a política de odds: a do request tem prioridade sobre a do usuário
+func (s *Server) oddsPolicy(ctx context.Context, req dto.PlaceBetRequest) (odds.Policy, error) {
+	if req.OddsPolicy != "" {
+		return odds.NormalizePolicy(req.OddsPolicy, req.OddsTolerancePct)
+	}
+	mode, tol, found, err := s.repo.GetOddsPolicy(ctx, req.UserID)
+	if err != nil {
+		// na dúvida, a política mais restritiva
+		s.log.Warn("load user odds policy", zap.String("userId", req.UserID), zap.Error(err))
+		return odds.DefaultPolicy, nil
+	}
+	if !found {
+		return odds.DefaultPolicy, nil
+	}
+	return odds.Policy{Mode: mode, TolerancePct: tol}, nil
+}
+
+// oddsPolicyRoute consulta (GET) ou define (PUT) a política de odds padrão do usuário
+// path: /users/{userId}/odds-policy
+func (s *Server) oddsPolicyRoute(w http.ResponseWriter, r *http.Request) {
+	userID, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/users/"), "/")
+	if userID == "" || sub != "odds-policy" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		policy, err := s.oddsPolicy(r.Context(), dto.PlaceBetRequest{UserID: userID})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, dto.OddsPolicyResponse{UserID: userID, Mode: policy.Mode, TolerancePct: policy.TolerancePct})
+	case http.MethodPut:
+		var req dto.OddsPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		policy, err := odds.NormalizePolicy(req.Mode, req.TolerancePct)
+		if err != nil {
+			http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.repo.SetOddsPolicy(r.Context(), userID, policy.Mode, policy.TolerancePct); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, dto.OddsPolicyResponse{UserID: userID, Mode: policy.Mode, TolerancePct: policy.TolerancePct})
+	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// toBet converte o request em aposta simples (campos de topo) ou acumulada (legs)
+func toBet(req dto.PlaceBetRequest) *repo.Bet {
+	b := &repo.Bet{UserID: req.UserID, StakeCents: req.StakeCents}
+	if len(req.Legs) == 0 {
+		b.BetType = repo.BetTypeSingle
+		b.EventID, b.Market, b.Selection = req.EventID, req.Market, req.Selection
+		b.Legs = []repo.Leg{{EventID: req.EventID, Market: req.Market, Selection: req.Selection, OddValue: req.OddValue}}
+		return b
+	}
+	b.BetType = repo.BetTypeAccumulator
+	if len(req.Legs) == 1 {
+		// bilhete de uma seleção é uma aposta simples
+		b.BetType = repo.BetTypeSingle
+		b.EventID, b.Market, b.Selection = req.Legs[0].EventID, req.Legs[0].Market, req.Legs[0].Selection
+	}
+	for _, l := range req.Legs {
+		b.Legs = append(b.Legs, repo.Leg{EventID: l.EventID, Market: l.Market, Selection: l.Selection, OddValue: l.OddValue})
+	}
+	return b
+}
+
+// listBets retorna o histórico de apostas do usuário com filtros e paginação por cursor
+// Query: userId (obrigatório), status, eventId, from/to (RFC3339), limit, cursor
+func (s *Server) listBets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := repo.BetFilter{
+		UserID:  q.Get("userId"),
+		Status:  strings.ToUpper(q.Get("status")),
+		EventID: q.Get("eventId"),
+		Cursor:  q.Get("cursor"),
+	}
+	if f.UserID == "" {
+		http.Error(w, "userId required", http.StatusBadRequest)
 		return
 	}
-	// path: /bets/{id}
-	id := r.URL.Path[len("/bets/"):]
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	bets, next, err := s.repo.ListBets(r.Context(), f)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		s.log.Error("list bets", zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.BetHistoryResponse{Bets: make([]dto.BetDetailResponse, 0, len(bets)), NextCursor: next}
+	for _, b := range bets {
+		resp.Bets = append(resp.Bets, toBetDetail(b))
+	}
+	writeJSON(w, resp)
+}
+
+func toBetDetail(b repo.BetDetail) dto.BetDetailResponse {
+	d := dto.BetDetailResponse{
+		BetID:        b.ID,
+		UserID:       b.UserID,
+		SlipID:       b.SlipID,
+		BetType:      b.BetType,
+		Status:       b.Status,
+		StakeCents:   b.StakeCents,
+		OddValue:     b.OddValue,
+		PotentialWin: b.PotentialWin,
+		CashoutCents: b.CashoutCents,
+		Legs:         make([]dto.BetLegResponse, 0, len(b.Legs)),
+		Transitions:  make([]dto.BetTransitionResponse, 0, len(b.Transitions)),
+		CreatedAt:    b.CreatedAt,
+		UpdatedAt:    b.UpdatedAt,
+	}
+	for _, l := range b.Legs {
+		d.Legs = append(d.Legs, dto.BetLegResponse{EventID: l.EventID, Market: l.Market, Selection: l.Selection, OddValue: l.OddValue, Status: l.Status})
+	}
+	for _, t := range b.Transitions {
+		d.Transitions = append(d.Transitions, dto.BetTransitionResponse{OldStatus: t.OldStatus, NewStatus: t.NewStatus, Reason: t.Reason, CreatedAt: t.CreatedAt})
+	}
+	return d
+}
+
+// betRoutes encaminha as rotas de uma aposta: /bets/{id} e /bets/{id}/cashout
+func (s *Server) betRoutes(w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(r.URL.Path[len("/bets/"):], "/")
 	if id == "" {
 		http.Error(w, "betId required", http.StatusBadRequest)
 		return
 	}
+	switch sub {
+	case "":
+		s.getBetStatus(w, r, id)
+	case "cashout":
+		s.cashoutBet(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) getBetStatus(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
 	st, err := s.repo.GetStatus(r.Context(), id)
 	if err != nil {
@@ -125,6 +410,62 @@ func (s *Server) getBetStatus(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, dto.BetStatusResponse{BetID: id, Status: st})
 }
 
+// cashoutBet cota (GET) ou executa (POST) o cash-out da aposta
+func (s *Server) cashoutBet(w http.ResponseWriter, r *http.Request, id string) {
+	var (
+		offer cashout.Offer
+		err   error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		offer, err = s.cashout.Quote(r.Context(), id)
+	case http.MethodPost:
+		var req dto.CashoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AmountCents <= 0 {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		offer, err = s.cashout.Execute(r.Context(), id, req.AmountCents)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, cashout.ErrPayoutPending):
+		// aposta já encerrada; o crédito será refeito pela recuperação
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(toCashoutResponse(offer, "payout pending"))
+		return
+	case errors.Is(err, cashout.ErrPriceChanged):
+		// preço mudou desde a cotação: devolve a nova oferta para o cliente confirmar
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(toCashoutResponse(offer, "price changed"))
+		return
+	case errors.Is(err, cashout.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	case errors.Is(err, cashout.ErrNotCashable), errors.Is(err, cashout.ErrPriceUnavailable):
+		http.Error(w, "cashout unavailable: "+err.Error(), http.StatusConflict)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, toCashoutResponse(offer, ""))
+}
+
+func toCashoutResponse(o cashout.Offer, msg string) dto.CashoutResponse {
+	st := "OFFERED"
+	if o.CashedOut {
+		st = repo.StatusCashedOut
+	}
+	return dto.CashoutResponse{BetID: o.BetID, Status: st, AmountCents: o.AmountCents, StakeCents: o.StakeCents, Message: msg}
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
@@ -0,0 +1,77 @@
+package odds
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Modos de aceitação de mudança de odd entre a cotação do cliente e a colocação
+const (
+	// PolicyExact só aceita a odd exatamente igual à informada (padrão)
+	PolicyExact = "EXACT"
+	// PolicyAcceptHigher aceita qualquer odd maior; odds menores só dentro da tolerância
+	PolicyAcceptHigher = "ACCEPT_HIGHER"
+	// PolicyAcceptAny aceita odds maiores ou menores dentro da tolerância (obrigatória, > 0)
+	PolicyAcceptAny = "ACCEPT_ANY"
+)
+
+// MaxTolerancePct limita a tolerância configurável
+const MaxTolerancePct = 50.0
+
+var ErrInvalidPolicy = errors.New("invalid odds policy")
+
+// Policy define como a odd atual do servidor é aceita quando difere da odd pedida
+type Policy struct {
+	Mode         string
+	TolerancePct float64 // variação percentual aceita em relação à odd pedida
+}
+
+// DefaultPolicy mantém o comportamento original: qualquer mudança retorna 409
+var DefaultPolicy = Policy{Mode: PolicyExact}
+
+// NormalizePolicy valida e normaliza o modo (case-insensitive) e a tolerância.
+// ACCEPT_ANY exige tolerância positiva: sem ela a política aceitaria qualquer variação
+func NormalizePolicy(mode string, tolerancePct float64) (Policy, error) {
+	p := Policy{Mode: strings.ToUpper(strings.TrimSpace(mode)), TolerancePct: tolerancePct}
+	switch p.Mode {
+	case PolicyExact, PolicyAcceptHigher, PolicyAcceptAny:
+	default:
+		return Policy{}, ErrInvalidPolicy
+	}
+	if p.TolerancePct < 0 || p.TolerancePct > MaxTolerancePct {
+		return Policy{}, ErrInvalidPolicy
+	}
+	if p.Mode == PolicyAcceptAny && p.TolerancePct == 0 {
+		return Policy{}, ErrInvalidPolicy
+	}
+	return p, nil
+}
+
+// Accept decide se a odd atual pode substituir a odd pedida; quando aceita, a aposta
+// é colocada pela odd atual (accepted). Tolerância 0 só aceita a odd pedida (ex.: políticas
+// ACCEPT_ANY gravadas antes da validação exigir tolerância)
+func (p Policy) Accept(requested, current float64) (accepted float64, ok bool) {
+	requested, current = round3(requested), round3(current)
+	if current == requested {
+		return current, true
+	}
+
+	// a variação é comparada em pontos percentuais arredondados: 2.0 -> 1.9 é exatamente 5%,
+	// mas em ponto flutuante fica acima de 0.05
+	tol := p.TolerancePct
+	diff := round3(math.Abs(current-requested) / requested * 100)
+	switch p.Mode {
+	case PolicyAcceptHigher:
+		if current > requested || diff <= tol {
+			return current, true
+		}
+	case PolicyAcceptAny:
+		if diff <= tol {
+			return current, true
+		}
+	}
+	return 0, false
+}
+
+func round3(v float64) float64 { return math.Round(v*1000) / 1000 }
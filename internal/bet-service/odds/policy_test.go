@@ -0,0 +1,80 @@
+package odds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		tol     float64
+		want    Policy
+		wantErr bool
+	}{
+		{name: "exact", mode: "EXACT", want: Policy{Mode: PolicyExact}},
+		{name: "case and spaces", mode: " accept_higher ", tol: 2, want: Policy{Mode: PolicyAcceptHigher, TolerancePct: 2}},
+		{name: "higher without tolerance", mode: "ACCEPT_HIGHER", want: Policy{Mode: PolicyAcceptHigher}},
+		{name: "any with tolerance", mode: "ACCEPT_ANY", tol: 5, want: Policy{Mode: PolicyAcceptAny, TolerancePct: 5}},
+		{name: "any at max tolerance", mode: "ACCEPT_ANY", tol: MaxTolerancePct, want: Policy{Mode: PolicyAcceptAny, TolerancePct: MaxTolerancePct}},
+		{name: "any without tolerance", mode: "ACCEPT_ANY", wantErr: true},
+		{name: "negative tolerance", mode: "ACCEPT_ANY", tol: -1, wantErr: true},
+		{name: "tolerance above max", mode: "ACCEPT_HIGHER", tol: MaxTolerancePct + 0.1, wantErr: true},
+		{name: "unknown mode", mode: "ACCEPT_LOWER", tol: 5, wantErr: true},
+		{name: "empty mode", mode: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePolicy(tt.mode, tt.tol)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPolicy) {
+					t.Fatalf("err = %v, want ErrInvalidPolicy", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("NormalizePolicy(%q, %v) = %+v, %v; want %+v", tt.mode, tt.tol, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyAccept(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		requested float64
+		current   float64
+		want      float64
+		ok        bool
+	}{
+		{"exact same", Policy{Mode: PolicyExact}, 2.0, 2.0, 2.0, true},
+		{"exact same after rounding", Policy{Mode: PolicyExact}, 2.0, 2.0004, 2.0, true},
+		{"exact higher", Policy{Mode: PolicyExact}, 2.0, 2.1, 0, false},
+		{"exact lower", Policy{Mode: PolicyExact}, 2.0, 1.9, 0, false},
+		{"exact ignores tolerance", Policy{Mode: PolicyExact, TolerancePct: 10}, 2.0, 1.95, 0, false},
+
+		{"higher accepts any rise", Policy{Mode: PolicyAcceptHigher}, 2.0, 5.0, 5.0, true},
+		{"higher drop without tolerance", Policy{Mode: PolicyAcceptHigher}, 2.0, 1.99, 0, false},
+		{"higher drop at tolerance edge", Policy{Mode: PolicyAcceptHigher, TolerancePct: 5}, 2.0, 1.9, 1.9, true},
+		{"higher drop past tolerance", Policy{Mode: PolicyAcceptHigher, TolerancePct: 5}, 2.0, 1.89, 0, false},
+
+		{"any rise at tolerance edge", Policy{Mode: PolicyAcceptAny, TolerancePct: 5}, 2.0, 2.1, 2.1, true},
+		{"any rise past tolerance", Policy{Mode: PolicyAcceptAny, TolerancePct: 5}, 2.0, 2.11, 0, false},
+		{"any drop at tolerance edge", Policy{Mode: PolicyAcceptAny, TolerancePct: 5}, 2.0, 1.9, 1.9, true},
+		{"any drop past tolerance", Policy{Mode: PolicyAcceptAny, TolerancePct: 5}, 2.0, 1.89, 0, false},
+		// políticas gravadas sem tolerância não aceitam variação
+		{"any without tolerance rise", Policy{Mode: PolicyAcceptAny}, 2.0, 2.01, 0, false},
+		{"any without tolerance drop", Policy{Mode: PolicyAcceptAny}, 2.0, 1.5, 0, false},
+		{"any without tolerance same", Policy{Mode: PolicyAcceptAny}, 2.0, 2.0, 2.0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.policy.Accept(tt.requested, tt.current)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("Accept(%v, %v) = %v, %v; want %v, %v", tt.requested, tt.current, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Publisher publica o evento bet_placed no Kafka
+type Publisher interface {
+	PublishBetPlaced(context.Context, events.BetPlaced) error
+}
+
+// Relay lê o outbox do bet-service e publica as mensagens liberadas no Kafka
+// A entrega é at-least-once: a mensagem só é marcada SENT após o publish
+type Relay struct {
+	Log       *zap.Logger
+	Repo      *repo.Postgres
+	Publisher Publisher
+
+	Interval  time.Duration // intervalo de polling
+	BatchSize int
+
+	OnPublished func(n int) // métricas
+	OnFailed    func(n int) // métricas
+	OnPending   func(n int) // métricas (gauge)
+
+	wake chan struct{}
+}
+
+// NewRelay cria o relay com os parâmetros padrão de polling
+func NewRelay(log *zap.Logger, r *repo.Postgres, p Publisher) *Relay {
+	return &Relay{
+		Log:       log,
+		Repo:      r,
+		Publisher: p,
+		Interval:  time.Second,
+		BatchSize: 100,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Notify antecipa o próximo ciclo do relay (ex.: logo após liberar uma mensagem)
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run executa o polling do outbox até o contexto ser cancelado
+func (r *Relay) Run(ctx context.Context) {
+	t := time.NewTicker(r.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-r.wake:
+		}
+		r.drain(ctx)
+	}
+}
+
+// drain publica lotes enquanto houver mensagens disponíveis
+func (r *Relay) drain(ctx context.Context) {
+	for {
+		sent, failed, err := r.Repo.PublishPending(ctx, r.BatchSize, func(m repo.OutboxMessage) error {
+			var e events.BetPlaced
+			if err := json.Unmarshal(m.Payload, &e); err != nil {
+				return err
+			}
+			pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			return r.Publisher.PublishBetPlaced(pctx, e)
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				r.Log.Warn("outbox relay", zap.Error(err))
+			}
+			return
+		}
+		if sent > 0 && r.OnPublished != nil {
+			r.OnPublished(sent)
+		}
+		if failed > 0 {
+			r.Log.Warn("outbox publish failed", zap.Int("failed", failed))
+			if r.OnFailed != nil {
+				r.OnFailed(failed)
+			}
+		}
+		if r.OnPending != nil {
+			if n, err := r.Repo.CountPendingOutbox(ctx); err == nil {
+				r.OnPending(n)
+			}
+		}
+		// lote incompleto ou com falhas: aguarda o próximo ciclo
+		if sent+failed < r.BatchSize || failed > 0 {
+			return
+		}
+	}
+}
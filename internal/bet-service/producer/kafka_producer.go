@@ -22,5 +22,5 @@ func NewKafkaPublisher(w *kafka.Writer, topic string) *KafkaPublisher {
 func (p *KafkaPublisher) PublishBetPlaced(ctx context.Context, e events.BetPlaced) error {
 	e.TsUnixMs = time.Now().UnixMilli()
 	b, _ := json.Marshal(e)
-	return p.Writer.WriteMessages(ctx, kafka.Message{Value: b})
+	return p.Writer.WriteMessages(ctx, kafka.Message{Key: []byte(e.BetID), Value: b})
 }
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+)
+
+// StatusCashedOut é o status final de uma aposta encerrada antecipadamente
+const StatusCashedOut = betstate.CashedOut
+
+// GetWithLegs carrega a aposta com suas seleções (e o status de cada uma)
+func (p *Postgres) GetWithLegs(ctx context.Context, betID string) (*Bet, error) {
+	b := &Bet{ID: betID}
+	var slipID sql.NullString
+	var cashout sql.NullInt64
+	if err := p.db.QueryRowContext(ctx, `
+		SELECT user_id, bet_type, slip_id, stake_cents, odd_value, status, cashout_cents, created_at, updated_at
+		FROM bets WHERE id=$1`, betID,
+	).Scan(&b.UserID, &b.BetType, &slipID, &b.StakeCents, &b.OddValue, &b.Status, &cashout, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	b.SlipID, b.CashoutCents = slipID.String, cashout.Int64
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT event_id, market, selection, odd_value, status
+		FROM bet_legs WHERE bet_id=$1 ORDER BY leg_no`, betID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l Leg
+		if err := rows.Scan(&l.EventID, &l.Market, &l.Selection, &l.OddValue, &l.Status); err != nil {
+			return nil, err
+		}
+		b.Legs = append(b.Legs, l)
+	}
+	return b, rows.Err()
+}
+
+// CashOut encerra a aposta CONFIRMED como CASHED_OUT pelo valor informado e registra a
+// transição em bet_transactions. A transição é condicional: retorna false se a aposta já
+// foi liquidada ou encerrada (a liquidação também só parte de CONFIRMED)
+func (p *Postgres) CashOut(ctx context.Context, betID string, amountCents int64) (bool, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ok, err := betstate.TransitionTx(ctx, tx, betID, betstate.Confirmed, betstate.CashedOut, "cashout")
+	if err != nil || !ok {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bets SET cashout_cents=$2, cashed_out_at=NOW() WHERE id=$1`, betID, amountCents); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// MarkCashoutPaid registra que o crédito do cash-out foi confirmado pelo wallet-service
+func (p *Postgres) MarkCashoutPaid(ctx context.Context, betID string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bets SET cashout_paid_at=NOW()
+		WHERE id=$1 AND status='CASHED_OUT' AND cashout_paid_at IS NULL`, betID)
+	return err
+}
+
+// ListUnpaidCashouts retorna apostas encerradas por cash-out há mais de olderThan cujo
+// crédito na carteira ainda não foi confirmado (ID, UserID e CashoutCents preenchidos)
+func (p *Postgres) ListUnpaidCashouts(ctx context.Context, olderThan time.Duration, limit int) ([]Bet, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, user_id, cashout_cents
+		FROM bets
+		WHERE status='CASHED_OUT' AND cashout_paid_at IS NULL
+		  AND cashed_out_at < NOW() - $1 * INTERVAL '1 millisecond'
+		ORDER BY cashed_out_at
+		LIMIT $2`, olderThan.Milliseconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Bet
+	for rows.Next() {
+		var b Bet
+		if err := rows.Scan(&b.ID, &b.UserID, &b.CashoutCents); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
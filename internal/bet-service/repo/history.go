@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Limites de página do histórico de apostas
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor indica um cursor de paginação malformado
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Transition é uma mudança de status registrada em bet_transactions
+type Transition struct {
+	OldStatus string
+	NewStatus string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// BetDetail é a aposta com os dados exibidos no histórico do usuário
+type BetDetail struct {
+	Bet
+	PotentialWin int64
+	Transitions  []Transition
+}
+
+// BetFilter define os filtros do histórico; campos vazios não filtram
+// Cursor é o valor opaco retornado como NextCursor na página anterior
+type BetFilter struct {
+	UserID  string
+	Status  string
+	EventID string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Cursor  string
+}
+
+// ListBets retorna uma página do histórico do usuário, da aposta mais recente para a
+// mais antiga, e o cursor da próxima página (vazio na última)
+func (p *Postgres) ListBets(ctx context.Context, f BetFilter) ([]BetDetail, string, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.Limit > MaxPageSize {
+		f.Limit = MaxPageSize
+	}
+
+	where := []string{"b.user_id = $1"}
+	args := []any{f.UserID}
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != "" {
+		add("b.status = $%d", f.Status)
+	}
+	if f.EventID != "" {
+		add("EXISTS (SELECT 1 FROM bet_legs l WHERE l.bet_id = b.id AND l.event_id = $%d)", f.EventID)
+	}
+	if !f.From.IsZero() {
+		add("b.created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("b.created_at < $%d", f.To)
+	}
+	if f.Cursor != "" {
+		ts, id, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, ts, id)
+		where = append(where, fmt.Sprintf("(b.created_at, b.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, f.Limit+1)
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT b.id, b.user_id, b.bet_type, b.slip_id, b.stake_cents, b.odd_value, b.potential_win,
+		       b.status, b.cashout_cents, b.created_at, b.updated_at
+		FROM bets b
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var out []BetDetail
+	for rows.Next() {
+		var d BetDetail
+		var slipID sql.NullString
+		var cashout sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.UserID, &d.BetType, &slipID, &d.StakeCents, &d.OddValue, &d.PotentialWin,
+			&d.Status, &cashout, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, "", err
+		}
+		d.SlipID, d.CashoutCents = slipID.String, cashout.Int64
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(out) > f.Limit {
+		out = out[:f.Limit]
+		last := out[len(out)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	if err := p.loadDetails(ctx, out); err != nil {
+		return nil, "", err
+	}
+	return out, next, nil
+}
+
+// loadDetails carrega seleções e transições das apostas da página em duas consultas
+func (p *Postgres) loadDetails(ctx context.Context, bets []BetDetail) error {
+	if len(bets) == 0 {
+		return nil
+	}
+	ids := make([]string, len(bets))
+	idx := make(map[string]int, len(bets))
+	for i, b := range bets {
+		ids[i] = b.ID
+		idx[b.ID] = i
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT bet_id, event_id, market, selection, odd_value, status
+		FROM bet_legs WHERE bet_id::text = ANY($1)
+		ORDER BY bet_id, leg_no`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var betID string
+		var l Leg
+		if err := rows.Scan(&betID, &l.EventID, &l.Market, &l.Selection, &l.OddValue, &l.Status); err != nil {
+			return err
+		}
+		b := &bets[idx[betID]]
+		b.Legs = append(b.Legs, l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	trows, err := p.db.QueryContext(ctx, `
+		SELECT bet_id, old_status, new_status, reason, created_at
+		FROM bet_transactions WHERE bet_id::text = ANY($1)
+		ORDER BY bet_id, created_at, id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer trows.Close()
+	for trows.Next() {
+		var betID string
+		var oldSt, newSt, reason sql.NullString
+		var t Transition
+		if err := trows.Scan(&betID, &oldSt, &newSt, &reason, &t.CreatedAt); err != nil {
+			return err
+		}
+		t.OldStatus, t.NewStatus, t.Reason = oldSt.String, newSt.String, reason.String
+		b := &bets[idx[betID]]
+		b.Transitions = append(b.Transitions, t)
+	}
+	return trows.Err()
+}
+
+// encodeCursor gera o cursor opaco a partir da última aposta da página
+func encodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeCursor(c string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if _, err := uuid.Parse(id); !ok || err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return t, id, nil
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Validade das chaves de idempotência
+const (
+	IdempotencyTTL = 24 * time.Hour
+	// IdempotencyLockTTL libera chaves cuja requisição foi interrompida sem gravar resposta
+	IdempotencyLockTTL = time.Minute
+)
+
+var (
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with a different payload")
+	ErrIdempotencyInFlight = errors.New("request with this idempotency key is in progress")
+)
+
+// IdempotentResponse é a resposta original gravada para uma chave.
+// Body nil com BetID ou SlipID indica requisição que falhou depois de criar a aposta:
+// a retentativa deve responder com o estado atual da aposta ou do bilhete
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+	BetID      string
+	SlipID     string
+}
+
+// ClaimIdempotencyKey reserva a chave para uma nova requisição.
+// Retorna (nil, nil) quando a chave foi reservada e a requisição deve ser processada,
+// a resposta original quando a chave já foi concluída com o mesmo payload,
+// ErrIdempotencyMismatch se o payload difere e ErrIdempotencyInFlight se ainda está em andamento.
+// Chaves expiradas (IdempotencyTTL) ou abandonadas (IdempotencyLockTTL) são reaproveitadas;
+// chaves presas a uma aposta criada só expiram pelo IdempotencyTTL.
+func (p *Postgres) ClaimIdempotencyKey(ctx context.Context, userID, key, requestHash string) (*IdempotentResponse, error) {
+	res, err := p.db.ExecContext(ctx, `
+		INSERT INTO bet_idempotency_keys (user_id, idem_key, request_hash)
+		VALUES ($1,$2,$3)
+		ON CONFLICT (user_id, idem_key) DO UPDATE
+		SET request_hash=EXCLUDED.request_hash, status_code=NULL, response_body=NULL,
+		    bet_id=NULL, slip_id=NULL, created_at=NOW(), completed_at=NULL
+		WHERE bet_idempotency_keys.created_at < NOW() - $4 * INTERVAL '1 millisecond'
+		   OR (bet_idempotency_keys.completed_at IS NULL
+		       AND bet_idempotency_keys.created_at < NOW() - $5 * INTERVAL '1 millisecond')`,
+		userID, key, requestHash, IdempotencyTTL.Milliseconds(), IdempotencyLockTTL.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil, nil
+	}
+
+	var hash string
+	var status sql.NullInt64
+	var body []byte
+	var betID, slipID sql.NullString
+	if err := p.db.QueryRowContext(ctx, `
+		SELECT request_hash, status_code, response_body, bet_id, slip_id
+		FROM bet_idempotency_keys WHERE user_id=$1 AND idem_key=$2`, userID, key,
+	).Scan(&hash, &status, &body, &betID, &slipID); err != nil {
+		return nil, err
+	}
+	if hash != requestHash {
+		return nil, ErrIdempotencyMismatch
+	}
+	if body == nil && !betID.Valid && !slipID.Valid {
+		return nil, ErrIdempotencyInFlight
+	}
+	return &IdempotentResponse{StatusCode: int(status.Int64), Body: body, BetID: betID.String, SlipID: slipID.String}, nil
+}
+
+// CompleteIdempotencyKey grava a resposta original da requisição
+func (p *Postgres) CompleteIdempotencyKey(ctx context.Context, userID, key string, statusCode int, body []byte) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bet_idempotency_keys SET status_code=$3, response_body=$4, completed_at=NOW()
+		WHERE user_id=$1 AND idem_key=$2`, userID, key, statusCode, string(body))
+	return err
+}
+
+// AttachIdempotencyBet prende a chave à aposta (ou bilhete) criada por uma requisição
+// que falhou depois da criação; retentativas recebem o estado atual em vez de uma nova aposta
+func (p *Postgres) AttachIdempotencyBet(ctx context.Context, userID, key, betID, slipID string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bet_idempotency_keys SET bet_id=$3, slip_id=$4, completed_at=NOW()
+		WHERE user_id=$1 AND idem_key=$2`, userID, key, nullIfEmpty(betID), nullIfEmpty(slipID))
+	return err
+}
+
+// ReleaseIdempotencyKey remove a reserva da chave para permitir nova tentativa
+// (usado quando a requisição falhou sem criar aposta)
+func (p *Postgres) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	_, err := p.db.ExecContext(ctx, `
+		DELETE FROM bet_idempotency_keys
+		WHERE user_id=$1 AND idem_key=$2 AND completed_at IS NULL`, userID, key)
+	return err
+}
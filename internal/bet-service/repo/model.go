@@ -2,16 +2,52 @@ package repo
 
 import "time"
 
+// Tipos de aposta
+const (
+	BetTypeSingle      = "SINGLE"
+	BetTypeAccumulator = "ACCUMULATOR"
+)
+
 // Bet é o modelo persistido no Postgres.
+// Em apostas simples EventID/Market/Selection espelham a única seleção;
+// em acumuladas ficam vazios e as seleções estão em Legs (tabela bet_legs).
 type Bet struct {
-	ID         string
-	UserID     string
-	EventID    string
-	Market     string
-	Selection  string
-	StakeCents int64
-	OddValue   float64
-	Status     string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID           string
+	UserID       string
+	BetType      string
+	SlipID       string // bilhete pai (apostas sistema); vazio nas demais
+	EventID      string
+	Market       string
+	Selection    string
+	StakeCents   int64
+	OddValue     float64 // odd combinada (produto das seleções)
+	Legs         []Leg
+	Status       string
+	CashoutCents int64 // valor pago no cash-out (apenas em CASHED_OUT)
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Leg é uma seleção de uma aposta (bet_legs)
+type Leg struct {
+	EventID   string
+	Market    string
+	Selection string
+	OddValue  float64
+	Status    string // OPEN | WON | LOST | VOID (preenchido na leitura)
+}
+
+// Slip é um bilhete de aposta sistema (bet_slips): cada linha é uma aposta
+// própria com o mesmo valor unitário
+type Slip struct {
+	ID             string
+	UserID         string
+	SystemType     string
+	UnitStakeCents int64
+	Lines          []*Bet
+}
+
+// TotalStakeCents retorna o valor total do bilhete (valor unitário × linhas)
+func (s *Slip) TotalStakeCents() int64 {
+	return s.UnitStakeCents * int64(len(s.Lines))
 }
@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// GetOddsPolicy retorna a política de aceitação de odds do usuário; found=false se não houver
+func (p *Postgres) GetOddsPolicy(ctx context.Context, userID string) (mode string, tolerancePct float64, found bool, err error) {
+	err = p.db.QueryRowContext(ctx, `
+		SELECT mode, tolerance_pct FROM user_odds_policies WHERE user_id=$1`, userID,
+	).Scan(&mode, &tolerancePct)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+	return mode, tolerancePct, true, nil
+}
+
+// SetOddsPolicy grava (ou substitui) a política de aceitação de odds do usuário
+func (p *Postgres) SetOddsPolicy(ctx context.Context, userID, mode string, tolerancePct float64) error {
+	_, err := p.db.ExecContext(ctx, `
+		INSERT INTO user_odds_policies (user_id, mode, tolerance_pct, updated_at)
+		VALUES ($1,$2,$3,NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET mode=EXCLUDED.mode, tolerance_pct=EXCLUDED.tolerance_pct, updated_at=NOW()`,
+		userID, mode, tolerancePct)
+	return err
+}
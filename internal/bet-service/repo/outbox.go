@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Status das mensagens do outbox
+const (
+	OutboxHeld    = "HELD"
+	OutboxPending = "PENDING"
+	OutboxSent    = "SENT"
+	OutboxDiscard = "DISCARDED"
+)
+
+// OutboxMessage é uma mensagem do outbox pronta para publicação
+type OutboxMessage struct {
+	ID       int64
+	BetID    string
+	Topic    string
+	Key      string
+	Payload  []byte
+	Attempts int
+}
+
+// maxOutboxBackoff limita o intervalo entre tentativas de uma mesma mensagem
+const maxOutboxBackoff = time.Minute
+
+// PublishPending reivindica até limit mensagens PENDING (SKIP LOCKED, seguro com várias réplicas),
+// chama publish para cada uma e registra o resultado da entrega
+// Em falha, incrementa attempts, guarda o erro e reagenda com backoff linear
+func (p *Postgres) PublishPending(ctx context.Context, limit int, publish func(OutboxMessage) error) (sent, failed int, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, bet_id, topic, msg_key, payload, attempts
+		FROM bet_outbox
+		WHERE status='PENDING' AND available_at <= NOW()
+		ORDER BY available_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	var batch []OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		if err := rows.Scan(&m.ID, &m.BetID, &m.Topic, &m.Key, &m.Payload, &m.Attempts); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, m := range batch {
+		if perr := publish(m); perr != nil {
+			backoff := time.Duration(m.Attempts+1) * 2 * time.Second
+			if backoff > maxOutboxBackoff {
+				backoff = maxOutboxBackoff
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE bet_outbox
+				SET attempts=attempts+1, last_error=$2, available_at=NOW() + $3 * INTERVAL '1 millisecond'
+				WHERE id=$1`, m.ID, perr.Error(), backoff.Milliseconds()); err != nil {
+				return sent, failed, err
+			}
+			failed++
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE bet_outbox SET status='SENT', attempts=attempts+1, last_error=NULL, sent_at=NOW()
+			WHERE id=$1`, m.ID); err != nil {
+			return sent, failed, err
+		}
+		sent++
+	}
+
+	return sent, failed, tx.Commit()
+}
+
+// CountPendingOutbox retorna o total de mensagens ainda não publicadas
+func (p *Postgres) CountPendingOutbox(ctx context.Context) (int, error) {
+	var n int
+	err := p.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM bet_outbox WHERE status='PENDING'`).Scan(&n)
+	return n, err
+}
+
+// betPlacedPayload monta o evento bet_placed gravado no outbox
+func betPlacedPayload(id string, b *Bet) ([]byte, error) {
+	legs := make([]events.BetLegRef, 0, len(b.Legs))
+	for _, l := range b.Legs {
+		legs = append(legs, events.BetLegRef{
+			EventID:   l.EventID,
+			Market:    l.Market,
+			Selection: l.Selection,
+			OddValue:  l.OddValue,
+		})
+	}
+	return json.Marshal(events.BetPlaced{
+		BetID:       id,
+		UserID:      b.UserID,
+		BetType:     b.BetType,
+		SlipID:      b.SlipID,
+		EventID:     b.EventID,
+		Market:      b.Market,
+		Selection:   b.Selection,
+		StakeCents:  b.StakeCents,
+		OddValue:    b.OddValue,
+		Legs:        legs,
+		ReservedRef: id,
+	})
+}
@@ -8,28 +8,148 @@ import (
 )
 
 // Postgres implementa operações de persistência de apostas em banco Postgres
-type Postgres struct{ db *sql.DB }
+// outboxTopic: tópico gravado nas mensagens do outbox (bet_placed)
+type Postgres struct {
+	db          *sql.DB
+	outboxTopic string
+}
 
 // NewPostgres retorna uma instância do repositório de apostas
-func NewPostgres(db *sql.DB) *Postgres { return &Postgres{db: db} }
+func NewPostgres(db *sql.DB, outboxTopic string) *Postgres {
+	return &Postgres{db: db, outboxTopic: outboxTopic}
+}
 
-// CreatePending insere uma nova aposta com status PENDING_CONFIRMATION
+// CreatePending insere uma nova aposta com status PENDING_CONFIRMATION e, na mesma
+// transação, grava o evento bet_placed no outbox (HELD até a reserva de saldo)
+// e o passo inicial da saga de colocação
 func (p *Postgres) CreatePending(ctx context.Context, b *Bet) (string, error) {
 	id := uuid.NewString()
-	_, err := p.db.ExecContext(ctx, `
-		INSERT INTO bets (id,user_id,event_id,market,selection,stake_cents,odd_value,status)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,'PENDING_CONFIRMATION')`,
-		id, b.UserID, b.EventID, b.Market, b.Selection, b.StakeCents, b.OddValue,
-	)
+
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return "", err
 	}
+	defer tx.Rollback()
+
+	if err := p.insertBet(ctx, tx, id, b); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
 	return id, nil
 }
 
+// CreateSlip grava o bilhete de aposta sistema e todas as suas linhas (aposta, seleções,
+// outbox HELD e saga) em uma única transação; retorna os IDs das linhas na ordem de s.Lines
+func (p *Postgres) CreateSlip(ctx context.Context, s *Slip) (string, []string, error) {
+	slipID := uuid.NewString()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bet_slips (id,user_id,system_type,unit_stake_cents,line_count,total_stake_cents)
+		VALUES ($1,$2,$3,$4,$5,$6)`,
+		slipID, s.UserID, s.SystemType, s.UnitStakeCents, len(s.Lines), s.TotalStakeCents(),
+	); err != nil {
+		return "", nil, err
+	}
+
+	ids := make([]string, 0, len(s.Lines))
+	for _, b := range s.Lines {
+		id := uuid.NewString()
+		b.SlipID = slipID
+		if err := p.insertBet(ctx, tx, id, b); err != nil {
+			return "", nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", nil, err
+	}
+	return slipID, ids, nil
+}
+
+// insertBet grava a aposta PENDING_CONFIRMATION com suas seleções, o evento bet_placed
+// retido (HELD) no outbox e o passo inicial da saga
+func (p *Postgres) insertBet(ctx context.Context, tx *sql.Tx, id string, b *Bet) error {
+	payload, err := betPlacedPayload(id, b)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bets (id,user_id,bet_type,slip_id,event_id,market,selection,stake_cents,odd_value,status)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,'PENDING_CONFIRMATION')`,
+		id, b.UserID, b.BetType, nullIfEmpty(b.SlipID), nullIfEmpty(b.EventID), nullIfEmpty(b.Market), nullIfEmpty(b.Selection), b.StakeCents, b.OddValue,
+	); err != nil {
+		return err
+	}
+
+	for i, l := range b.Legs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO bet_legs (bet_id,leg_no,event_id,market,selection,odd_value)
+			VALUES ($1,$2,$3,$4,$5,$6)`,
+			id, i+1, l.EventID, l.Market, l.Selection, l.OddValue,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bet_outbox (bet_id, topic, msg_key, payload, status)
+		VALUES ($1,$2,$3,$4,'HELD')`,
+		id, p.outboxTopic, id, string(payload),
+	); err != nil {
+		return err
+	}
+
+	return insertSaga(ctx, tx, id, b)
+}
+
 // GetStatus retorna o status atual de uma aposta pelo betID
 func (p *Postgres) GetStatus(ctx context.Context, betID string) (string, error) {
 	var s string
 	err := p.db.QueryRowContext(ctx, `SELECT status FROM bets WHERE id=$1`, betID).Scan(&s)
 	return s, err
 }
+
+// GetSlip carrega o bilhete sistema com suas linhas (ID, tipo, valor, odd e status atual)
+func (p *Postgres) GetSlip(ctx context.Context, slipID string) (*Slip, error) {
+	s := &Slip{ID: slipID}
+	if err := p.db.QueryRowContext(ctx, `
+		SELECT user_id, system_type, unit_stake_cents FROM bet_slips WHERE id=$1`, slipID,
+	).Scan(&s.UserID, &s.SystemType, &s.UnitStakeCents); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, bet_type, stake_cents, odd_value, status
+		FROM bets WHERE slip_id=$1 ORDER BY created_at, id`, slipID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		b := &Bet{UserID: s.UserID, SlipID: slipID}
+		if err := rows.Scan(&b.ID, &b.BetType, &b.StakeCents, &b.OddValue, &b.Status); err != nil {
+			return nil, err
+		}
+		s.Lines = append(s.Lines, b)
+	}
+	return s, rows.Err()
+}
+
+// nullIfEmpty converte string vazia em NULL (colunas opcionais em acumuladas)
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
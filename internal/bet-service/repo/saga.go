@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+)
+
+// Passos e status da saga de criação de aposta
+const (
+	SagaCreated      = "CREATED"
+	SagaReserved     = "RESERVED"
+	SagaPublished    = "PUBLISHED"
+	SagaCompensating = "COMPENSATING"
+
+	SagaRunning     = "RUNNING"
+	SagaCompleted   = "COMPLETED"
+	SagaCompensated = "COMPENSATED"
+)
+
+// Saga é o estado persistido da colocação de uma aposta
+type Saga struct {
+	BetID       string
+	UserID      string
+	ReserveRef  string
+	AmountCents int64
+	Step        string
+	Status      string
+	UpdatedAt   time.Time
+}
+
+// insertSaga grava o passo inicial da saga na transação de criação da aposta
+func insertSaga(ctx context.Context, tx *sql.Tx, betID string, b *Bet) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO bet_placement_sagas (bet_id, user_id, reserve_ref, amount_cents, step, status)
+		VALUES ($1,$2,$3,$4,'CREATED','RUNNING')`,
+		betID, b.UserID, betID, b.StakeCents)
+	return err
+}
+
+// SetSagaStep registra o avanço da saga para um novo passo
+func (p *Postgres) SetSagaStep(ctx context.Context, betID, step string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bet_placement_sagas SET step=$2, updated_at=NOW()
+		WHERE bet_id=$1 AND status='RUNNING'`, betID, step)
+	return err
+}
+
+// SetSlipSagaStep avança de uma vez as sagas de todas as linhas de um bilhete sistema,
+// mantendo as linhas sempre no mesmo passo (a recuperação trata o bilhete por inteiro)
+func (p *Postgres) SetSlipSagaStep(ctx context.Context, slipID, step string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bet_placement_sagas s SET step=$2, updated_at=NOW()
+		FROM bets b
+		WHERE b.id = s.bet_id AND b.slip_id=$1 AND s.status='RUNNING'`, slipID, step)
+	return err
+}
+
+// SetSagaError guarda o último erro da saga, mantendo o passo atual
+func (p *Postgres) SetSagaError(ctx context.Context, betID, msg string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE bet_placement_sagas SET last_error=$2, updated_at=NOW()
+		WHERE bet_id=$1`, betID, msg)
+	return err
+}
+
+// CompleteSaga libera o evento bet_placed no outbox e conclui a saga na mesma transação
+func (p *Postgres) CompleteSaga(ctx context.Context, betID string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bet_outbox SET status='PENDING', available_at=NOW()
+		WHERE bet_id=$1 AND status='HELD'`, betID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bet_placement_sagas SET step='PUBLISHED', status='COMPLETED', updated_at=NOW()
+		WHERE bet_id=$1 AND status='RUNNING'`, betID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CompensateSaga marca a aposta como FAILED (com histórico em bet_transactions),
+// descarta o evento retido no outbox e encerra a saga como COMPENSATED
+// Deve ser chamado após a reserva de saldo ter sido liberada
+func (p *Postgres) CompensateSaga(ctx context.Context, betID, reason string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := betstate.TransitionTx(ctx, tx, betID, betstate.PendingConfirmation, betstate.Failed, reason); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bet_outbox SET status='DISCARDED'
+		WHERE bet_id=$1 AND status='HELD'`, betID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bet_placement_sagas SET step='COMPENSATING', status='COMPENSATED', last_error=$2, updated_at=NOW()
+		WHERE bet_id=$1 AND status='RUNNING'`, betID, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListStaleSagas retorna sagas em andamento sem progresso há mais de olderThan
+func (p *Postgres) ListStaleSagas(ctx context.Context, olderThan time.Duration, limit int) ([]Saga, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT bet_id, user_id, reserve_ref, amount_cents, step, status, updated_at
+		FROM bet_placement_sagas
+		WHERE status='RUNNING' AND updated_at < NOW() - $1 * INTERVAL '1 millisecond'
+		ORDER BY updated_at
+		LIMIT $2`, olderThan.Milliseconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Saga
+	for rows.Next() {
+		var s Saga
+		if err := rows.Scan(&s.BetID, &s.UserID, &s.ReserveRef, &s.AmountCents, &s.Step, &s.Status, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
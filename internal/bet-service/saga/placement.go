@@ -0,0 +1,162 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet"
+	walletdto "github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet/dto"
+)
+
+// ErrReserveFailed indica que a reserva de saldo falhou e a aposta foi compensada
+var ErrReserveFailed = errors.New("wallet reserve failed")
+
+// Notifier antecipa a publicação das mensagens liberadas no outbox
+type Notifier interface {
+	Notify()
+}
+
+// Placement orquestra a saga de criação de aposta:
+// CREATED (aposta + outbox HELD) -> RESERVED (saldo reservado) -> PUBLISHED (outbox liberado)
+// Em falha, compensa liberando a reserva e marcando a aposta como FAILED
+type Placement struct {
+	Log    *zap.Logger
+	Repo   *repo.Postgres
+	Wallet *wallet.Client
+	Outbox Notifier
+
+	OnCompensated func() // métricas
+	OnResumed     func() // métricas
+}
+
+// Place executa a saga para uma nova aposta e retorna o betID
+func (p *Placement) Place(ctx context.Context, b *repo.Bet) (string, error) {
+	betID, err := p.Repo.CreatePending(ctx, b)
+	if err != nil {
+		return "", err
+	}
+
+	// Reserva de saldo (external_ref = betID)
+	if _, err := p.Wallet.Reserve(ctx, b.UserID, b.StakeCents, betID); err != nil {
+		p.Log.Warn("wallet reserve failed", zap.String("betId", betID), zap.Error(err))
+		p.compensate(context.WithoutCancel(ctx), betID, b.UserID, betID, "wallet_reserve_failed")
+		return betID, ErrReserveFailed
+	}
+	if err := p.Repo.SetSagaStep(ctx, betID, repo.SagaReserved); err != nil {
+		// a recuperação retoma a partir de CREATED compensando a reserva
+		return betID, err
+	}
+
+	// Libera o evento bet_placed; o relay publica no Kafka
+	if err := p.Repo.CompleteSaga(ctx, betID); err != nil {
+		// a recuperação retoma a partir de RESERVED liberando o outbox
+		return betID, err
+	}
+	p.Outbox.Notify()
+	return betID, nil
+}
+
+// PlaceSlip executa a saga de um bilhete de aposta sistema: todas as linhas são criadas
+// juntas, o total é reservado em uma única chamada ao wallet (uma reserva por linha) e as
+// linhas avançam de passo juntas, de modo que o bilhete é colocado ou compensado por inteiro
+func (p *Placement) PlaceSlip(ctx context.Context, s *repo.Slip) (string, []string, error) {
+	slipID, betIDs, err := p.Repo.CreateSlip(ctx, s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	items := make([]walletdto.ReserveBatchItem, 0, len(betIDs))
+	for i, id := range betIDs {
+		items = append(items, walletdto.ReserveBatchItem{ExternalRef: id, AmountCents: s.Lines[i].StakeCents})
+	}
+	if err := p.Wallet.ReserveBatch(ctx, s.UserID, items); err != nil {
+		p.Log.Warn("wallet reserve failed", zap.String("slipId", slipID), zap.Error(err))
+		cctx := context.WithoutCancel(ctx)
+		for _, id := range betIDs {
+			p.compensate(cctx, id, s.UserID, id, "wallet_reserve_failed")
+		}
+		return slipID, betIDs, ErrReserveFailed
+	}
+	if err := p.Repo.SetSlipSagaStep(ctx, slipID, repo.SagaReserved); err != nil {
+		return slipID, betIDs, err
+	}
+
+	for _, id := range betIDs {
+		if err := p.Repo.CompleteSaga(ctx, id); err != nil {
+			// linhas restantes seguem em RESERVED e são concluídas pela recuperação
+			return slipID, betIDs, err
+		}
+	}
+	p.Outbox.Notify()
+	return slipID, betIDs, nil
+}
+
+// compensate libera a reserva (se existir) e marca a aposta como FAILED
+// Falhas ficam registradas na saga e são refeitas pela recuperação
+func (p *Placement) compensate(ctx context.Context, betID, userID, reserveRef, reason string) {
+	if err := p.Repo.SetSagaStep(ctx, betID, repo.SagaCompensating); err != nil {
+		p.Log.Error("saga step", zap.String("betId", betID), zap.Error(err))
+		return
+	}
+	if err := p.Wallet.Release(ctx, userID, reserveRef); err != nil && !errors.Is(err, wallet.ErrReservationNotFound) {
+		p.Log.Error("saga release reservation", zap.String("betId", betID), zap.Error(err))
+		_ = p.Repo.SetSagaError(ctx, betID, err.Error())
+		return
+	}
+	if err := p.Repo.CompensateSaga(ctx, betID, reason); err != nil {
+		p.Log.Error("saga compensate", zap.String("betId", betID), zap.Error(err))
+		return
+	}
+	if p.OnCompensated != nil {
+		p.OnCompensated()
+	}
+}
+
+// Recover retoma ou compensa sagas interrompidas (ex.: bet-service reiniciado no meio da colocação)
+// CREATED: a reserva pode ou não existir -> compensa
+// RESERVED: saldo reservado -> conclui liberando o outbox
+// COMPENSATING: refaz a compensação
+func (p *Placement) Recover(ctx context.Context, staleAfter time.Duration) error {
+	sagas, err := p.Repo.ListStaleSagas(ctx, staleAfter, 100)
+	if err != nil {
+		return err
+	}
+	for _, s := range sagas {
+		switch s.Step {
+		case repo.SagaCreated, repo.SagaCompensating:
+			p.Log.Info("saga rollback", zap.String("betId", s.BetID), zap.String("step", s.Step))
+			p.compensate(ctx, s.BetID, s.UserID, s.ReserveRef, "placement_interrupted")
+		case repo.SagaReserved:
+			p.Log.Info("saga resume", zap.String("betId", s.BetID))
+			if err := p.Repo.CompleteSaga(ctx, s.BetID); err != nil {
+				p.Log.Error("saga resume", zap.String("betId", s.BetID), zap.Error(err))
+				continue
+			}
+			p.Outbox.Notify()
+			if p.OnResumed != nil {
+				p.OnResumed()
+			}
+		}
+	}
+	return nil
+}
+
+// RunRecovery executa Recover na inicialização e depois periodicamente
+func (p *Placement) RunRecovery(ctx context.Context, interval, staleAfter time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := p.Recover(ctx, staleAfter); err != nil && ctx.Err() == nil {
+			p.Log.Warn("saga recovery", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
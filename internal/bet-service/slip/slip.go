@@ -0,0 +1,61 @@
+package slip
+
+import (
+	"errors"
+	"math"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+)
+
+// Limites de um bilhete acumulado
+const (
+	MaxLegs = 20
+	// MaxCombinedOdd respeita a precisão de bets.odd_value (NUMERIC(8,3))
+	MaxCombinedOdd = 99999.0
+)
+
+var (
+	ErrInvalidLeg     = errors.New("invalid leg")
+	ErrTooManyLegs    = errors.New("too many legs")
+	ErrDuplicateEvent = errors.New("legs must be on distinct events")
+	ErrOddTooHigh     = errors.New("combined odd too high")
+)
+
+// Validate verifica as seleções de um bilhete: campos obrigatórios, odds positivas,
+// limite de seleções e no máximo uma seleção por evento
+func Validate(legs []repo.Leg) error {
+	if len(legs) == 0 {
+		return ErrInvalidLeg
+	}
+	if len(legs) > MaxLegs {
+		return ErrTooManyLegs
+	}
+	seen := make(map[string]struct{}, len(legs))
+	for _, l := range legs {
+		if l.EventID == "" || l.Market == "" || l.Selection == "" || l.OddValue <= 0 {
+			return ErrInvalidLeg
+		}
+		if _, dup := seen[l.EventID]; dup {
+			return ErrDuplicateEvent
+		}
+		seen[l.EventID] = struct{}{}
+	}
+	if CombinedOdd(legs) > MaxCombinedOdd {
+		return ErrOddTooHigh
+	}
+	return nil
+}
+
+// CombinedOdd retorna o produto das odds das seleções com 3 casas decimais
+func CombinedOdd(legs []repo.Leg) float64 {
+	odd := 1.0
+	for _, l := range legs {
+		odd *= l.OddValue
+	}
+	return math.Round(odd*1000) / 1000
+}
+
+// PotentialWin calcula o retorno potencial como em bets.potential_win
+func PotentialWin(stakeCents int64, odd float64) int64 {
+	return int64(math.Round(float64(stakeCents) * odd))
+}
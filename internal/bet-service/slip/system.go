@@ -0,0 +1,77 @@
+package slip
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-service/repo"
+)
+
+// System descreve uma aposta sistema: quantidade de seleções e o menor tamanho de
+// combinação gerado (1 = inclui simples, 2 = a partir das duplas)
+type System struct {
+	Selections int
+	MinSize    int
+}
+
+// Systems lista as apostas sistema aceitas
+//
+//	TRIXIE  3 seleções: 3 duplas + 1 tripla                    = 4 linhas
+//	PATENT  3 seleções: 3 simples + 3 duplas + 1 tripla        = 7 linhas
+//	YANKEE  4 seleções: 6 duplas + 4 triplas + 1 quádrupla     = 11 linhas
+//	LUCKY15 4 seleções: 4 simples + 6 duplas + 4 triplas + 1   = 15 linhas
+var Systems = map[string]System{
+	"TRIXIE":  {Selections: 3, MinSize: 2},
+	"PATENT":  {Selections: 3, MinSize: 1},
+	"YANKEE":  {Selections: 4, MinSize: 2},
+	"LUCKY15": {Selections: 4, MinSize: 1},
+}
+
+var (
+	ErrUnknownSystem   = errors.New("unknown system type")
+	ErrSystemSelection = errors.New("wrong number of selections for system type")
+)
+
+// ValidateSystem verifica o tipo de sistema e as seleções do bilhete
+func ValidateSystem(systemType string, legs []repo.Leg) error {
+	sys, ok := Systems[strings.ToUpper(systemType)]
+	if !ok {
+		return ErrUnknownSystem
+	}
+	if len(legs) != sys.Selections {
+		return ErrSystemSelection
+	}
+	return Validate(legs)
+}
+
+// ExpandSystem valida as seleções e expande o sistema em suas linhas: todas as
+// combinações de MinSize até todas as seleções, em ordem de tamanho
+func ExpandSystem(systemType string, legs []repo.Leg) ([][]repo.Leg, error) {
+	if err := ValidateSystem(systemType, legs); err != nil {
+		return nil, err
+	}
+	sys := Systems[strings.ToUpper(systemType)]
+
+	var lines [][]repo.Leg
+	for size := sys.MinSize; size <= len(legs); size++ {
+		lines = append(lines, combinations(legs, size)...)
+	}
+	return lines, nil
+}
+
+// combinations retorna as combinações de k seleções preservando a ordem original
+func combinations(legs []repo.Leg, k int) [][]repo.Leg {
+	var out [][]repo.Leg
+	var walk func(start int, cur []repo.Leg)
+	walk = func(start int, cur []repo.Leg) {
+		if len(cur) == k {
+			out = append(out, append([]repo.Leg(nil), cur...))
+			return
+		}
+		for i := start; i < len(legs); i++ {
+			walk(i+1, append(cur, legs[i]))
+		}
+	}
+	walk(0, make([]repo.Leg, 0, k))
+	return out
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,6 +12,9 @@ import (
 	walletdto "github.com/radieske/sports-bet-platform-poc/internal/bet-service/wallet/dto"
 )
 
+// ErrReservationNotFound indica que não existe reserva para o external_ref informado
+var ErrReservationNotFound = errors.New("wallet reservation not found")
+
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
@@ -41,3 +45,55 @@ func (c *Client) Reserve(ctx context.Context, userID string, cents int64, extern
 	}
 	return out.ReservationID, nil
 }
+
+// ReserveBatch reserva o total de um lote de referências em uma única chamada (tudo ou nada)
+func (c *Client) ReserveBatch(ctx context.Context, userID string, items []walletdto.ReserveBatchItem) error {
+	body, _ := json.Marshal(walletdto.ReserveBatchRequest{UserID: userID, Items: items})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/wallet/reserve-batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("wallet reserve-batch http %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Release libera uma reserva PENDING devolvendo o saldo (idempotente no wallet-service)
+// Retorna ErrReservationNotFound se a reserva nunca chegou a ser criada
+func (c *Client) Release(ctx context.Context, userID, externalRef string) error {
+	body, _ := json.Marshal(walletdto.RefundRequest{UserID: userID, ExternalRef: externalRef})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/wallet/refund", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return ErrReservationNotFound
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("wallet refund http %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Payout credita um valor na carteira (ex.: cash-out); idempotente por external_ref no wallet-service
+func (c *Client) Payout(ctx context.Context, userID string, cents int64, externalRef, betID string) error {
+	body, _ := json.Marshal(walletdto.PayoutRequest{UserID: userID, AmountCents: cents, ExternalRef: externalRef, BetID: betID})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/wallet/payout", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("wallet payout http %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package dto
+
+// PayoutRequest representa o payload para creditar um valor na carteira no wallet-service.
+type PayoutRequest struct {
+	UserID      string `json:"userId"`
+	AmountCents int64  `json:"amount_cents"`
+	ExternalRef string `json:"external_ref"`
+	BetID       string `json:"betId,omitempty"`
+}
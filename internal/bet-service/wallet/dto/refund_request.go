@@ -0,0 +1,7 @@
+package dto
+
+// RefundRequest representa o payload para liberar (estornar) uma reserva no wallet-service.
+type RefundRequest struct {
+	UserID      string `json:"userId"`
+	ExternalRef string `json:"external_ref"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// ReserveBatchRequest representa o payload para reservar o total de várias referências no wallet-service.
+type ReserveBatchRequest struct {
+	UserID string             `json:"userId"`
+	Items  []ReserveBatchItem `json:"items"`
+}
+
+// ReserveBatchItem é uma reserva do lote (ex.: uma linha de aposta sistema).
+type ReserveBatchItem struct {
+	ExternalRef string `json:"external_ref"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// ReserveBatchResponse representa a resposta do endpoint de reserva em lote do wallet-service.
+type ReserveBatchResponse struct {
+	Reservations []ReserveResponse `json:"reservations"`
+	TotalCents   int64             `json:"total_cents"`
+}
@@ -0,0 +1,230 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/resolver"
+	"github.com/radieske/sports-bet-platform-poc/internal/bet-settlement/wallet"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// maxAttempts limita as tentativas de liquidar um mesmo resultado antes de avançar o offset;
+// o resultado já está gravado e o restante fica para a varredura (RunSweep)
+const maxAttempts = 3
+
+// Settler consome resultados de partidas do Kafka e liquida as apostas confirmadas do evento
+// Callbacks de métricas podem ser usadas para monitoramento de cada etapa
+type Settler struct {
+	Log    *zap.Logger
+	Reader *kafka.Reader
+	Repo   *repo.Postgres
+	Wallet *wallet.Client
+
+	OnConsumed func()       // métricas (counter++)
+	OnSettled  func(string) // métricas por resultado (WON/LOST/VOID)
+	OnSwept    func(int)    // métrica (gauge) de eventos pendentes encontrados pela varredura
+	OnError    func(string) // métricas por fase
+}
+
+// Run inicia o loop de consumo; o offset só é confirmado após o resultado definitivo ser
+// gravado e a liquidação ser tentada
+func (s *Settler) Run(ctx context.Context) error {
+	for {
+		m, err := s.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.Log.Warn("kafka fetch failed", zap.Error(err))
+			s.onError("read")
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+
+		if s.OnConsumed != nil {
+			s.OnConsumed()
+		}
+
+		var res events.MatchResult
+		if err := json.Unmarshal(m.Value, &res); err != nil || res.EventID == "" {
+			s.Log.Warn("invalid match result", zap.Error(err))
+			s.onError("decode")
+		} else if !res.IsFinal() {
+			// placares ao vivo interessam apenas a placares/scoreboards
+			s.Log.Debug("skip non-final result", zap.String("eventId", res.EventID), zap.String("status", res.Status))
+		} else if !s.saveResult(ctx, res) {
+			// encerrando: o offset não é confirmado e o resultado é reentregue
+			return ctx.Err()
+		} else {
+			for attempt := 1; attempt <= maxAttempts; attempt++ {
+				if err = s.settleEvent(ctx, res); err == nil {
+					break
+				}
+				s.Log.Warn("settle event failed",
+					zap.String("eventId", res.EventID), zap.Int("attempt", attempt), zap.Error(err))
+				time.Sleep(time.Duration(500*attempt) * time.Millisecond)
+			}
+			if err != nil {
+				// o resultado está gravado: as apostas não liquidadas permanecem CONFIRMED e
+				// ficam para a varredura periódica (RunSweep)
+				s.Log.Error("settle event deferred to sweep", zap.String("eventId", res.EventID), zap.Error(err))
+			}
+		}
+
+		if err := s.Reader.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			s.Log.Warn("kafka commit failed", zap.Error(err))
+			s.onError("commit")
+		}
+	}
+}
+
+// saveResult grava o resultado definitivo antes da liquidação, tentando até conseguir: sem
+// ele a varredura não teria como retomar a liquidação depois que o offset avança.
+// Retorna false apenas se o contexto foi cancelado
+func (s *Settler) saveResult(ctx context.Context, res events.MatchResult) bool {
+	for attempt := 1; ; attempt++ {
+		err := s.Repo.SaveResult(ctx, res)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		s.Log.Warn("save match result failed",
+			zap.String("eventId", res.EventID), zap.Int("attempt", attempt), zap.Error(err))
+		s.onError("db_save_result")
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Duration(500*min(attempt, 10)) * time.Millisecond):
+		}
+	}
+}
+
+// Sweep liquida as apostas CONFIRMED de eventos com resultado gravado que o consumer não
+// conseguiu liquidar (falhas após maxAttempts, worker reiniciado no meio da liquidação)
+func (s *Settler) Sweep(ctx context.Context, grace time.Duration) error {
+	results, err := s.Repo.ListUnsettledResults(ctx, grace, 100)
+	if err != nil {
+		s.onError("db_list_results")
+		return err
+	}
+	if s.OnSwept != nil {
+		s.OnSwept(len(results))
+	}
+	for _, res := range results {
+		if err := s.settleEvent(ctx, res); err != nil {
+			s.Log.Warn("sweep settle event failed", zap.String("eventId", res.EventID), zap.Error(err))
+			continue
+		}
+		s.Log.Info("sweep settled event", zap.String("eventId", res.EventID))
+	}
+	return nil
+}
+
+// RunSweep executa Sweep na inicialização e depois periodicamente
+func (s *Settler) RunSweep(ctx context.Context, interval, grace time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.Sweep(ctx, grace); err != nil && ctx.Err() == nil {
+			s.Log.Warn("settlement sweep", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// settleEvent liquida as seleções do evento (inclusive de apostas ainda em PENDING_CONFIRMATION)
+// e as apostas CONFIRMED que ficaram decididas;
+// é seguro reexecutar pois seleções, crédito e transição de status são condicionais/idempotentes
+func (s *Settler) settleEvent(ctx context.Context, res events.MatchResult) error {
+	bets, err := s.Repo.ListOpenByEvent(ctx, res.EventID)
+	if err != nil {
+		s.onError("db_list")
+		return err
+	}
+
+	var lastErr error
+	for _, b := range bets {
+		if err := s.settleBet(ctx, b, res); err != nil {
+			s.Log.Warn("settle bet failed", zap.String("betId", b.ID), zap.Error(err))
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (s *Settler) settleBet(ctx context.Context, b repo.Bet, res events.MatchResult) error {
+	// 1) Resolve as seleções do evento que ainda estão em aberto
+	legs := make([]resolver.LegResult, 0, len(b.Legs))
+	var reason string
+	for _, l := range b.Legs {
+		status := l.Status
+		if l.EventID == res.EventID && status == resolver.OutcomeOpen {
+			var r string
+			status, r = resolver.Resolve(l.Market, l.Selection, res)
+			if _, err := s.Repo.SettleLeg(ctx, l.ID, status); err != nil {
+				s.onError("db_settle_leg")
+				return err
+			}
+			if reason == "" || status == resolver.OutcomeLost {
+				reason = r
+			}
+		}
+		legs = append(legs, resolver.LegResult{Status: status, OddValue: l.OddValue})
+	}
+
+	// 2) Decide a aposta; acumuladas aguardam os demais eventos. Apostas ainda não confirmadas
+	// ficam com as seleções liquidadas e são decididas pela varredura depois da confirmação
+	if b.Status != betstate.Confirmed {
+		s.Log.Debug("bet legs settled before confirmation", zap.String("betId", b.ID), zap.String("status", b.Status))
+		return nil
+	}
+	outcome, amount := resolver.Combine(b.StakeCents, legs)
+	if outcome == resolver.OutcomeOpen {
+		s.Log.Debug("bet waiting other legs", zap.String("betId", b.ID), zap.String("eventId", res.EventID))
+		return nil
+	}
+	if len(b.Legs) > 1 || reason == "" {
+		reason = "legs_" + strings.ToLower(outcome)
+	}
+
+	// 3) Crédito antes da transição: se o status falhar, a reentrega credita de novo sem duplicar
+	if amount > 0 {
+		if err := s.Wallet.Payout(ctx, b.UserID, amount, "bet-settle:"+b.ID, b.ID); err != nil {
+			s.onError("wallet_payout")
+			return err
+		}
+	}
+
+	settled, err := s.Repo.Settle(ctx, b.ID, outcome, reason)
+	if err != nil {
+		s.onError("db_settle")
+		return err
+	}
+	if settled {
+		s.Log.Info("bet settled", zap.String("betId", b.ID), zap.String("outcome", outcome), zap.Int64("amount", amount))
+		if s.OnSettled != nil {
+			s.OnSettled(outcome)
+		}
+	}
+	return nil
+}
+
+func (s *Settler) onError(stage string) {
+	if s.OnError != nil {
+		s.OnError(stage)
+	}
+}
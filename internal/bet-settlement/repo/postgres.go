@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/betstate"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Bet representa uma aposta aguardando liquidação, com todas as suas seleções
+type Bet struct {
+	ID           string
+	UserID       string
+	Status       string // PENDING_CONFIRMATION | CONFIRMED
+	StakeCents   int64
+	PotentialWin int64
+	Legs         []Leg
+}
+
+// Leg representa uma seleção da aposta (simples = 1 seleção, acumulada = N)
+type Leg struct {
+	ID        int64
+	LegNo     int
+	EventID   string
+	Market    string
+	Selection string
+	OddValue  float64
+	Status    string // OPEN | WON | LOST | VOID
+}
+
+// Postgres implementa as operações de liquidação de apostas em banco Postgres
+type Postgres struct{ db *sql.DB }
+
+// NewPostgres retorna uma instância do repositório de liquidação
+func NewPostgres(db *sql.DB) *Postgres { return &Postgres{db: db} }
+
+// ListOpenByEvent retorna as apostas CONFIRMED ou ainda em PENDING_CONFIRMATION com alguma
+// seleção no evento, carregando todas as seleções de cada aposta (inclusive de outros eventos).
+// As seleções das apostas pendentes são liquidadas junto com as demais; a aposta é decidida
+// quando for confirmada (varredura de resultados)
+func (p *Postgres) ListOpenByEvent(ctx context.Context, eventID string) ([]Bet, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT b.id, b.user_id, b.status, b.stake_cents, b.potential_win,
+		       l.id, l.leg_no, l.event_id, l.market, l.selection, l.odd_value, l.status
+		FROM bets b
+		JOIN bet_legs l ON l.bet_id = b.id
+		WHERE b.status IN ($2, $3)
+		  AND EXISTS (SELECT 1 FROM bet_legs e WHERE e.bet_id = b.id AND e.event_id=$1)
+		ORDER BY b.created_at, b.id, l.leg_no`, eventID, betstate.Confirmed, betstate.PendingConfirmation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Bet
+	for rows.Next() {
+		var b Bet
+		var l Leg
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Status, &b.StakeCents, &b.PotentialWin,
+			&l.ID, &l.LegNo, &l.EventID, &l.Market, &l.Selection, &l.OddValue, &l.Status); err != nil {
+			return nil, err
+		}
+		if n := len(out); n > 0 && out[n-1].ID == b.ID {
+			out[n-1].Legs = append(out[n-1].Legs, l)
+			continue
+		}
+		b.Legs = []Leg{l}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// SaveResult grava o resultado definitivo do evento (a última versão recebida prevalece)
+func (p *Postgres) SaveResult(ctx context.Context, res events.MatchResult) error {
+	payload, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO match_results (event_id, status, payload, received_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (event_id) DO UPDATE SET status=EXCLUDED.status, payload=EXCLUDED.payload`,
+		res.EventID, res.Status, payload)
+	return err
+}
+
+// ListUnsettledResults retorna os resultados gravados há mais de grace com apostas CONFIRMED
+// ainda pendentes no evento: seleção em aberto ou todas as seleções decididas sem a aposta
+// ter sido liquidada (ex.: crédito falhou depois de esgotar as tentativas do consumer, ou a
+// aposta foi confirmada depois que o resultado liquidou suas seleções)
+func (p *Postgres) ListUnsettledResults(ctx context.Context, grace time.Duration, limit int) ([]events.MatchResult, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT r.payload
+		FROM match_results r
+		WHERE r.received_at < NOW() - $1::interval
+		  AND EXISTS (
+		    SELECT 1 FROM bet_legs l JOIN bets b ON b.id = l.bet_id
+		    WHERE l.event_id = r.event_id AND b.status=$2
+		      AND (l.status='OPEN'
+		           OR NOT EXISTS (SELECT 1 FROM bet_legs o WHERE o.bet_id = b.id AND o.status='OPEN')))
+		ORDER BY r.received_at
+		LIMIT $3`, fmt.Sprintf("%d seconds", int(grace.Seconds())), betstate.Confirmed, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []events.MatchResult
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, err
+		}
+		var res events.MatchResult
+		if err := json.Unmarshal(payload, &res); err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
+
+// SettleLeg grava o resultado de uma seleção ainda em aberto
+// Retorna false se a seleção já havia sido liquidada (entrega duplicada)
+func (p *Postgres) SettleLeg(ctx context.Context, legID int64, status string) (bool, error) {
+	res, err := p.db.ExecContext(ctx, `
+		UPDATE bet_legs SET status=$1, settled_at=NOW()
+		WHERE id=$2 AND status='OPEN'`, status, legID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Settle move a aposta de CONFIRMED para o status final e registra a transição em bet_transactions
+// Retorna false se a aposta já havia sido liquidada (entrega duplicada)
+func (p *Postgres) Settle(ctx context.Context, betID, newStatus, reason string) (bool, error) {
+	return betstate.Transition(ctx, p.db, betID, betstate.Confirmed, newStatus, reason)
+}
@@ -0,0 +1,133 @@
+package resolver
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Resultados possíveis de uma aposta liquidada
+const (
+	OutcomeWon  = "WON"
+	OutcomeLost = "LOST"
+	OutcomeVoid = "VOID"
+)
+
+// Resolve decide se uma seleção venceu, perdeu ou foi anulada a partir do resultado da partida.
+// Prioriza o resultado por mercado informado pelo fornecedor; na ausência dele, deriva
+// o vencedor do placar final (1x2, ambas marcam, total de gols e handicap). Mercados que
+// não podem ser resolvidos e linhas inteiras empatadas (push) são anulados para devolver
+// o valor apostado.
+func Resolve(market, selection string, res events.MatchResult) (outcome, reason string) {
+	if !strings.EqualFold(res.Status, events.MatchStatusFinished) {
+		return OutcomeVoid, "match_" + strings.ToLower(res.Status)
+	}
+
+	sel := events.NormalizeSelection(selection)
+	winner, ok := winningSelection(market, res)
+	switch {
+	case !ok:
+		return OutcomeVoid, "market_not_resolved"
+	case winner == push:
+		return OutcomeVoid, "market_push"
+	case sel == winner:
+		return OutcomeWon, "selection_won"
+	}
+	return OutcomeLost, "selection_lost"
+}
+
+// push indica linha empatada (ex.: over/under 2 com 2 gols): a aposta é devolvida
+const push = "push"
+
+// winningSelection retorna a seleção vencedora de um mercado, se conhecida
+func winningSelection(market string, res events.MatchResult) (string, bool) {
+	key := events.NormalizeMarket(market)
+	for k, v := range res.Outcomes {
+		if events.NormalizeMarket(k) == key {
+			return events.NormalizeSelection(v), true
+		}
+	}
+
+	h, a := res.HomeScore, res.AwayScore
+	m, line := events.SplitMarketKey(key)
+	switch m {
+	case events.MarketMatchOdds:
+		switch {
+		case h > a:
+			return "home", true
+		case h < a:
+			return "away", true
+		default:
+			return "draw", true
+		}
+	case events.MarketBTTS:
+		if h > 0 && a > 0 {
+			return "yes", true
+		}
+		return "no", true
+	case events.MarketOverUnder:
+		l, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return "", false
+		}
+		return compare(float64(h+a)-l, "over", "under"), true
+	case events.MarketHandicap:
+		l, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return "", false
+		}
+		return compare(float64(h)+l-float64(a), "home", "away"), true
+	}
+	// placar exato depende das faixas cotadas pelo fornecedor ("other"): só com o resultado dele
+	return "", false
+}
+
+// compare escolhe a seleção pelo sinal da diferença; zero é push
+func compare(diff float64, above, below string) string {
+	switch {
+	case diff > 0:
+		return above
+	case diff < 0:
+		return below
+	}
+	return push
+}
+
+// OutcomeOpen indica que a aposta ainda tem seleções aguardando resultado
+const OutcomeOpen = "OPEN"
+
+// LegResult é o resultado de uma seleção usado para decidir a aposta inteira
+type LegResult struct {
+	Status   string // OPEN | WON | LOST | VOID
+	OddValue float64
+}
+
+// Combine decide o resultado de uma aposta a partir das suas seleções e o valor a creditar.
+// Uma seleção perdida perde a aposta imediatamente; seleções anuladas saem do cálculo
+// (odd 1.0) e, se todas forem anuladas, o valor apostado é devolvido. Enquanto houver
+// seleção em aberto a aposta continua CONFIRMED (OutcomeOpen).
+func Combine(stakeCents int64, legs []LegResult) (outcome string, payoutCents int64) {
+	open, voided := 0, 0
+	odd := 1.0
+	for _, l := range legs {
+		switch l.Status {
+		case OutcomeLost:
+			return OutcomeLost, 0
+		case OutcomeVoid:
+			voided++
+		case OutcomeWon:
+			odd *= l.OddValue
+		default:
+			open++
+		}
+	}
+	switch {
+	case open > 0:
+		return OutcomeOpen, 0
+	case voided == len(legs):
+		return OutcomeVoid, stakeCents
+	}
+	return OutcomeWon, int64(math.Round(float64(stakeCents) * odd))
+}
@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+func finished(home, away int) events.MatchResult {
+	return events.MatchResult{EventID: "E1", Status: events.MatchStatusFinished, HomeScore: home, AwayScore: away}
+}
+
+func TestResolveLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		market     string
+		selection  string
+		home, away int
+		want       string
+		wantReason string
+	}{
+		// over/under: linha inteira empatada é push; meia linha sempre decide
+		{"over below whole line", "over_under:2", "over", 1, 0, OutcomeLost, "selection_lost"},
+		{"over on whole line", "over_under:2", "over", 1, 1, OutcomeVoid, "market_push"},
+		{"under on whole line", "over_under:2", "under", 2, 0, OutcomeVoid, "market_push"},
+		{"over above whole line", "over_under:2", "over", 2, 1, OutcomeWon, "selection_won"},
+		{"over half line below", "over_under:2.5", "over", 1, 1, OutcomeLost, "selection_lost"},
+		{"under half line below", "over_under:2.5", "under", 1, 1, OutcomeWon, "selection_won"},
+		{"over half line above", "over_under:2.5", "over", 2, 1, OutcomeWon, "selection_won"},
+		{"under half line above", "over_under:2.5", "UNDER", 3, 0, OutcomeLost, "selection_lost"},
+		{"zero goals on half line", "over_under:0.5", "under", 0, 0, OutcomeWon, "selection_won"},
+		{"line notation normalized", "Over_Under:2.50", "under", 1, 1, OutcomeWon, "selection_won"},
+
+		// handicap: linha aplicada ao mandante
+		{"home -1 wins by two", "handicap:-1", "home", 2, 0, OutcomeWon, "selection_won"},
+		{"home -1 wins by one", "handicap:-1", "home", 2, 1, OutcomeVoid, "market_push"},
+		{"away +1 loses by one", "handicap:-1", "away", 2, 1, OutcomeVoid, "market_push"},
+		{"home -1 draws", "handicap:-1", "home", 1, 1, OutcomeLost, "selection_lost"},
+		{"home +1 loses by one", "handicap:1", "home", 0, 1, OutcomeVoid, "market_push"},
+		{"home -1.5 wins by one", "handicap:-1.5", "home", 1, 0, OutcomeLost, "selection_lost"},
+		{"away +1.5 loses by one", "handicap:-1.5", "away", 1, 0, OutcomeWon, "selection_won"},
+		{"home -1.5 wins by two", "handicap:-1.5", "1", 3, 1, OutcomeWon, "selection_won"},
+		{"home +0.5 draws", "handicap:+0.5", "home", 2, 2, OutcomeWon, "selection_won"},
+		{"home 0 draws", "handicap:0", "away", 0, 0, OutcomeVoid, "market_push"},
+
+		{"line missing", "over_under", "over", 3, 0, OutcomeVoid, "market_not_resolved"},
+		{"line not numeric", "handicap:abc", "home", 3, 0, OutcomeVoid, "market_not_resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := Resolve(tt.market, tt.selection, finished(tt.home, tt.away))
+			if got != tt.want || reason != tt.wantReason {
+				t.Fatalf("Resolve(%s, %s, %d-%d) = %s/%s; want %s/%s",
+					tt.market, tt.selection, tt.home, tt.away, got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestResolveSupplierOutcomeAndCancelled(t *testing.T) {
+	res := finished(1, 1)
+	res.Outcomes = map[string]string{"Over_Under:2.5": "over", "correct_score": "1-1"}
+	if got, _ := Resolve("over_under:2.5", "over", res); got != OutcomeWon {
+		t.Errorf("supplier outcome: got %s, want WON", got)
+	}
+	if got, _ := Resolve("correct_score", "1-1", res); got != OutcomeWon {
+		t.Errorf("correct_score with supplier outcome: got %s, want WON", got)
+	}
+	if got, reason := Resolve("correct_score", "1-1", finished(1, 1)); got != OutcomeVoid || reason != "market_not_resolved" {
+		t.Errorf("correct_score without supplier outcome: got %s/%s", got, reason)
+	}
+
+	cancelled := events.MatchResult{EventID: "E1", Status: events.MatchStatusCancelled}
+	if got, reason := Resolve("over_under:2.5", "over", cancelled); got != OutcomeVoid || reason != "match_cancelled" {
+		t.Errorf("cancelled: got %s/%s, want VOID/match_cancelled", got, reason)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		name   string
+		legs   []LegResult
+		want   string
+		payout int64
+	}{
+		{"single won", []LegResult{{OutcomeWon, 2.5}}, OutcomeWon, 2500},
+		{"single push", []LegResult{{OutcomeVoid, 1.9}}, OutcomeVoid, 1000},
+		{"push leg drops out", []LegResult{{OutcomeWon, 2.0}, {OutcomeVoid, 3.0}}, OutcomeWon, 2000},
+		{"all legs push", []LegResult{{OutcomeVoid, 2.0}, {OutcomeVoid, 3.0}}, OutcomeVoid, 1000},
+		{"lost beats open", []LegResult{{OutcomeOpen, 2.0}, {OutcomeLost, 3.0}}, OutcomeLost, 0},
+		{"open waits", []LegResult{{OutcomeWon, 2.0}, {OutcomeOpen, 3.0}}, OutcomeOpen, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, payout := Combine(1000, tt.legs)
+			if got != tt.want || payout != tt.payout {
+				t.Fatalf("Combine = %s/%d; want %s/%d", got, payout, tt.want, tt.payout)
+			}
+		})
+	}
+}
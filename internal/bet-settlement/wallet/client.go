@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client chama o wallet-service para creditar prêmios de apostas liquidadas
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+func New(base string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(base, "/"),
+		HTTP:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type payoutRequest struct {
+	UserID      string `json:"userId"`
+	AmountCents int64  `json:"amount_cents"`
+	ExternalRef string `json:"external_ref"`
+	BetID       string `json:"betId,omitempty"`
+}
+
+// Payout credita o valor na carteira; o wallet-service garante idempotência por externalRef
+func (c *Client) Payout(ctx context.Context, userID string, cents int64, externalRef, betID string) error {
+	body, _ := json.Marshal(payoutRequest{UserID: userID, AmountCents: cents, ExternalRef: externalRef, BetID: betID})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/wallet/payout", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("wallet payout http %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+-- 0005_pending_reservations_idx.up.sql
+-- Índice parcial para localizar reservas ainda PENDING pelo external_ref (betId),
+-- usado na detecção de apostas confirmadas com reserva não efetivada.
+
+CREATE INDEX IF NOT EXISTS idx_wallet_reservations_pending_ref
+  ON wallet_reservations(external_ref)
+  WHERE status = 'PENDING';
@@ -0,0 +1,30 @@
+-- 0006_bet_outbox.up.sql
+-- Outbox transacional do bet-service: o evento bet_placed é gravado na mesma
+-- transação da aposta e publicado no Kafka por um relay (entrega at-least-once).
+--
+-- status:
+--   HELD    -> gravado junto com a aposta, aguardando a reserva de saldo
+--   PENDING -> liberado para publicação pelo relay
+--   SENT    -> publicado no Kafka
+
+CREATE TABLE IF NOT EXISTS bet_outbox (
+  id            BIGSERIAL PRIMARY KEY,
+  bet_id        UUID NOT NULL REFERENCES bets(id) ON DELETE CASCADE,
+  topic         TEXT NOT NULL,
+  msg_key       TEXT NOT NULL,
+  payload       JSONB NOT NULL,
+  status        TEXT NOT NULL DEFAULT 'HELD',
+  attempts      INT NOT NULL DEFAULT 0,
+  last_error    TEXT,
+  available_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  sent_at       TIMESTAMPTZ,
+  CONSTRAINT chk_bet_outbox_status CHECK (status IN ('HELD','PENDING','SENT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_outbox_bet_id ON bet_outbox(bet_id);
+
+-- relay busca apenas mensagens liberadas, em ordem de disponibilidade
+CREATE INDEX IF NOT EXISTS idx_bet_outbox_pending
+  ON bet_outbox(available_at, id)
+  WHERE status = 'PENDING';
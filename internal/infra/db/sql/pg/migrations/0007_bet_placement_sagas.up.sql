@@ -0,0 +1,31 @@
+-- 0007_bet_placement_sagas.up.sql
+-- Saga de criação de aposta no bet-service. Cada passo é persistido para que um
+-- bet-service reiniciado possa retomar ou compensar colocações interrompidas.
+--
+-- step:   CREATED -> RESERVED -> PUBLISHED   (caminho feliz)
+--         COMPENSATING                       (desfazendo após falha)
+-- status: RUNNING | COMPLETED | COMPENSATED
+
+CREATE TABLE IF NOT EXISTS bet_placement_sagas (
+  bet_id        UUID PRIMARY KEY REFERENCES bets(id) ON DELETE CASCADE,
+  user_id       TEXT NOT NULL,
+  reserve_ref   TEXT NOT NULL,
+  amount_cents  BIGINT NOT NULL,
+  step          TEXT NOT NULL DEFAULT 'CREATED',
+  status        TEXT NOT NULL DEFAULT 'RUNNING',
+  last_error    TEXT,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT chk_saga_step CHECK (step IN ('CREATED','RESERVED','PUBLISHED','COMPENSATING')),
+  CONSTRAINT chk_saga_status CHECK (status IN ('RUNNING','COMPLETED','COMPENSATED'))
+);
+
+-- recuperação busca apenas sagas em andamento
+CREATE INDEX IF NOT EXISTS idx_bet_placement_sagas_running
+  ON bet_placement_sagas(updated_at)
+  WHERE status = 'RUNNING';
+
+-- mensagens do outbox de apostas compensadas nunca são publicadas
+ALTER TABLE bet_outbox DROP CONSTRAINT IF EXISTS chk_bet_outbox_status;
+ALTER TABLE bet_outbox ADD CONSTRAINT chk_bet_outbox_status
+  CHECK (status IN ('HELD','PENDING','SENT','DISCARDED'));
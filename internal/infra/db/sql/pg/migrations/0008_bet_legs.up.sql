@@ -0,0 +1,36 @@
+-- 0008_bet_legs.up.sql
+-- Apostas acumuladas (múltiplas): um bilhete com N seleções de eventos distintos.
+-- Toda aposta passa a ter suas seleções em bet_legs (simples = 1 seleção), e a
+-- liquidação acontece por seleção. bets.odd_value guarda a odd combinada.
+
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS bet_type TEXT NOT NULL DEFAULT 'SINGLE';
+
+-- Em acumuladas evento/mercado/seleção ficam apenas em bet_legs
+ALTER TABLE bets ALTER COLUMN event_id DROP NOT NULL;
+ALTER TABLE bets ALTER COLUMN market DROP NOT NULL;
+ALTER TABLE bets ALTER COLUMN selection DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS bet_legs (
+  id          BIGSERIAL PRIMARY KEY,
+  bet_id      UUID NOT NULL REFERENCES bets(id) ON DELETE CASCADE,
+  leg_no      INT NOT NULL,
+  event_id    TEXT NOT NULL,
+  market      TEXT NOT NULL,
+  selection   TEXT NOT NULL,
+  odd_value   NUMERIC(8,3) NOT NULL,
+  status      TEXT NOT NULL DEFAULT 'OPEN', -- OPEN | WON | LOST | VOID
+  settled_at  TIMESTAMPTZ,
+  UNIQUE (bet_id, leg_no),
+  CONSTRAINT chk_bet_legs_status CHECK (status IN ('OPEN','WON','LOST','VOID'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_legs_event_id ON bet_legs(event_id);
+
+-- Apostas simples existentes ganham sua seleção única em bet_legs
+INSERT INTO bet_legs (bet_id, leg_no, event_id, market, selection, odd_value, status, settled_at)
+SELECT id, 1, event_id, market, selection, odd_value,
+       CASE WHEN status IN ('WON','LOST','VOID') THEN status ELSE 'OPEN' END,
+       CASE WHEN status IN ('WON','LOST','VOID') THEN updated_at END
+FROM bets
+WHERE event_id IS NOT NULL
+ON CONFLICT (bet_id, leg_no) DO NOTHING;
@@ -0,0 +1,19 @@
+-- 0009_bet_slips.up.sql
+-- Apostas sistema (Trixie, Patent, Yankee, Lucky 15): um bilhete é expandido em todas
+-- as combinações de suas seleções. Cada combinação (linha) é gravada como uma aposta
+-- própria em bets, ligada ao bilhete pai em bet_slips.
+
+CREATE TABLE IF NOT EXISTS bet_slips (
+  id                UUID PRIMARY KEY,
+  user_id           TEXT NOT NULL,
+  system_type       TEXT NOT NULL,   -- TRIXIE | PATENT | YANKEE | LUCKY15
+  unit_stake_cents  BIGINT NOT NULL CHECK (unit_stake_cents > 0),
+  line_count        INT NOT NULL CHECK (line_count > 0),
+  total_stake_cents BIGINT NOT NULL CHECK (total_stake_cents > 0),
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_slips_user_id ON bet_slips(user_id);
+
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS slip_id UUID REFERENCES bet_slips(id);
+CREATE INDEX IF NOT EXISTS idx_bets_slip_id ON bets(slip_id) WHERE slip_id IS NOT NULL;
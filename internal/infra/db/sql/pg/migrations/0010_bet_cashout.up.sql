@@ -0,0 +1,7 @@
+-- 0010_bet_cashout.up.sql
+-- Cash-out: a aposta CONFIRMED é encerrada antecipadamente pelo valor ofertado
+-- (status CASHED_OUT). O valor pago fica na própria aposta para permitir refazer
+-- o crédito na carteira de forma idempotente.
+
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS cashout_cents BIGINT;
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS cashed_out_at TIMESTAMPTZ;
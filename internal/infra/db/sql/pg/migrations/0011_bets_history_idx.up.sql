@@ -0,0 +1,5 @@
+-- 0011_bets_history_idx.up.sql
+-- Histórico de apostas do usuário (GET /bets?userId=...) paginado por cursor
+-- na ordem (created_at DESC, id DESC).
+
+CREATE INDEX IF NOT EXISTS idx_bets_user_created ON bets(user_id, created_at DESC, id DESC);
@@ -0,0 +1,17 @@
+-- 0012_bet_idempotency_keys.up.sql
+-- Idempotency-Key do POST /bets: guarda o hash do payload e a resposta original
+-- para que retentativas do cliente não criem apostas duplicadas.
+-- response_body NULL indica requisição em andamento.
+
+CREATE TABLE IF NOT EXISTS bet_idempotency_keys (
+  user_id        TEXT NOT NULL,
+  idem_key       TEXT NOT NULL,
+  request_hash   TEXT NOT NULL,
+  status_code    INT,
+  response_body  JSONB,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  completed_at   TIMESTAMPTZ,
+  PRIMARY KEY (user_id, idem_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_idempotency_created ON bet_idempotency_keys(created_at);
@@ -0,0 +1,12 @@
+-- 0014_user_odds_policies.up.sql
+-- Política de aceitação de mudança de odds por usuário (EXACT | ACCEPT_HIGHER | ACCEPT_ANY).
+-- Usada quando o POST /bets não informa odds_policy.
+
+CREATE TABLE IF NOT EXISTS user_odds_policies (
+  user_id        TEXT PRIMARY KEY,
+  mode           TEXT NOT NULL,
+  tolerance_pct  NUMERIC(5,2) NOT NULL DEFAULT 0,
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT chk_user_odds_policy_mode CHECK (mode IN ('EXACT','ACCEPT_HIGHER','ACCEPT_ANY')),
+  CONSTRAINT chk_user_odds_policy_tolerance CHECK (tolerance_pct >= 0 AND tolerance_pct <= 50)
+);
@@ -0,0 +1,51 @@
+-- 0015_odds_multi_market.up.sql
+-- Modelo genérico de mercados: um evento tem N mercados identificados por
+-- (event_id, market, line), cada um com N seleções nomeadas.
+-- odds_current guarda as seleções em JSONB ([{"name":"over","odd":1.85}, ...]);
+-- odds_history passa a ter uma linha por seleção.
+
+-- Snapshot atual
+ALTER TABLE odds_current ADD COLUMN IF NOT EXISTS line TEXT NOT NULL DEFAULT '';
+ALTER TABLE odds_current ADD COLUMN IF NOT EXISTS selections JSONB NOT NULL DEFAULT '[]';
+
+UPDATE odds_current
+SET selections = jsonb_build_array(
+  jsonb_build_object('name','home','odd',home_odd),
+  jsonb_build_object('name','draw','odd',draw_odd),
+  jsonb_build_object('name','away','odd',away_odd)
+);
+
+ALTER TABLE odds_current DROP CONSTRAINT IF EXISTS odds_current_pkey;
+ALTER TABLE odds_current ADD PRIMARY KEY (event_id, market, line);
+ALTER TABLE odds_current
+  DROP COLUMN IF EXISTS home_odd,
+  DROP COLUMN IF EXISTS draw_odd,
+  DROP COLUMN IF EXISTS away_odd;
+
+-- Histórico
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS market TEXT NOT NULL DEFAULT '1x2';
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS line TEXT NOT NULL DEFAULT '';
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS selection TEXT;
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS odd NUMERIC(8,3);
+
+ALTER TABLE odds_history
+  ALTER COLUMN home_odd DROP NOT NULL,
+  ALTER COLUMN draw_odd DROP NOT NULL,
+  ALTER COLUMN away_odd DROP NOT NULL;
+
+-- Linhas antigas (1x2) viram uma linha por seleção
+INSERT INTO odds_history (event_id, market, line, selection, odd, version, updated_at)
+SELECT h.event_id, '1x2', '', s.name, s.odd, h.version, h.updated_at
+FROM odds_history h
+CROSS JOIN LATERAL (VALUES ('home', h.home_odd), ('draw', h.draw_odd), ('away', h.away_odd)) AS s(name, odd)
+WHERE h.selection IS NULL;
+
+DELETE FROM odds_history WHERE selection IS NULL;
+
+ALTER TABLE odds_history
+  DROP COLUMN IF EXISTS home_odd,
+  DROP COLUMN IF EXISTS draw_odd,
+  DROP COLUMN IF EXISTS away_odd;
+ALTER TABLE odds_history ALTER COLUMN market DROP DEFAULT;
+ALTER TABLE odds_history ALTER COLUMN selection SET NOT NULL;
+ALTER TABLE odds_history ALTER COLUMN odd SET NOT NULL;
@@ -0,0 +1,11 @@
+-- 0016_odds_market_status.up.sql
+-- Status do mercado informado pelo fornecedor (OPEN | SUSPENDED | CLOSED | SETTLED).
+-- O bet-service só aceita apostas em mercados OPEN.
+
+ALTER TABLE odds_current ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'OPEN';
+ALTER TABLE odds_current DROP CONSTRAINT IF EXISTS chk_odds_current_status;
+ALTER TABLE odds_current ADD CONSTRAINT chk_odds_current_status
+  CHECK (status IN ('OPEN','SUSPENDED','CLOSED','SETTLED'));
+
+-- Histórico registra o status vigente em cada preço (ex.: suspensões após gols)
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'OPEN';
@@ -0,0 +1,15 @@
+-- 0017_odds_version_guard.up.sql
+-- Reentregas do Kafka não duplicam o histórico: cada seleção tem no máximo um preço
+-- por versão do mercado. odds_current só é sobrescrito por versões mais novas.
+
+DELETE FROM odds_history a
+USING odds_history b
+WHERE a.id > b.id
+  AND a.event_id = b.event_id
+  AND a.market = b.market
+  AND a.line = b.line
+  AND a.selection = b.selection
+  AND a.version = b.version;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_odds_history_version
+  ON odds_history(event_id, market, line, selection, version);
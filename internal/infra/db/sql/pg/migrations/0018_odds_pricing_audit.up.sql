@@ -0,0 +1,13 @@
+-- 0018_odds_pricing_audit.up.sql
+-- O odds-processor publica as odds com a margem da casa. Para auditoria, o histórico
+-- guarda também a odd original do fornecedor, o overround dele e a margem/método aplicados.
+-- odd continua sendo a odd publicada (a mesma de odds_current e do cache).
+
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS raw_odd NUMERIC(8,3);
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS raw_overround NUMERIC(8,5);
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS margin NUMERIC(6,4);
+ALTER TABLE odds_history ADD COLUMN IF NOT EXISTS pricing_method TEXT;
+
+-- Modalidade e competição usadas na escolha da margem
+ALTER TABLE odds_current ADD COLUMN IF NOT EXISTS sport TEXT NOT NULL DEFAULT '';
+ALTER TABLE odds_current ADD COLUMN IF NOT EXISTS competition TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,14 @@
+-- 0019_odds_history_idx.up.sql
+-- Consultas de histórico de preços do odds-service:
+--  * por evento num intervalo de tempo, paginadas por (updated_at, id)
+--  * por seleção (evento, mercado, linha, seleção) num intervalo, para candles OHLC
+-- O índice antigo só por event_id fica coberto pelos novos.
+-- Com volume de produção, odds_history deve ser particionada por mês em updated_at.
+
+CREATE INDEX IF NOT EXISTS idx_odds_history_event_time
+  ON odds_history(event_id, updated_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_odds_history_selection_time
+  ON odds_history(event_id, market, line, selection, updated_at);
+
+DROP INDEX IF EXISTS idx_odds_history_event_id;
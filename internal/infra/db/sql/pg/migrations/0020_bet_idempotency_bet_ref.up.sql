@@ -0,0 +1,7 @@
+-- 0020_bet_idempotency_bet_ref.up.sql
+-- Chaves cuja requisição falhou depois de criar a aposta (ex.: erro após a reserva,
+-- concluída depois pela recuperação da saga) ficam presas à aposta ou ao bilhete criado:
+-- retentativas recebem o estado atual em vez de colocar uma segunda aposta.
+
+ALTER TABLE bet_idempotency_keys ADD COLUMN IF NOT EXISTS bet_id UUID;
+ALTER TABLE bet_idempotency_keys ADD COLUMN IF NOT EXISTS slip_id UUID;
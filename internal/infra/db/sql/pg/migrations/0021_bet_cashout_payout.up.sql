@@ -0,0 +1,17 @@
+-- 0021_bet_cashout_payout.up.sql
+-- A aposta é encerrada (CASHED_OUT) antes do crédito na carteira. cashout_paid_at marca o
+-- crédito confirmado; apostas CASHED_OUT sem a marca são recreditadas pela varredura do
+-- bet-service (idempotente por external_ref bet-cashout:{betId} no wallet-service).
+
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS cashout_paid_at TIMESTAMPTZ;
+
+-- cash-outs anteriores já creditados
+UPDATE bets b SET cashout_paid_at = b.cashed_out_at
+WHERE b.status = 'CASHED_OUT' AND b.cashout_paid_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM wallet_ledger l JOIN wallets w ON w.id = l.wallet_id
+    WHERE w.user_id = b.user_id AND l.operation_type = 'CREDIT'
+      AND l.description = 'payout:bet-cashout:' || b.id::text);
+
+CREATE INDEX IF NOT EXISTS idx_bets_cashout_unpaid
+  ON bets(cashed_out_at) WHERE status = 'CASHED_OUT' AND cashout_paid_at IS NULL;
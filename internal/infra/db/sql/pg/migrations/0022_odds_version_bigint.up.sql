@@ -0,0 +1,6 @@
+-- 0022_odds_version_bigint.up.sql
+-- A versão do mercado parte do relógio (Unix em segundos) no supplier-simulator;
+-- em INT estouraria em 2038 e quebraria a guarda odds_current.version < EXCLUDED.version.
+
+ALTER TABLE odds_current ALTER COLUMN version TYPE BIGINT;
+ALTER TABLE odds_history ALTER COLUMN version TYPE BIGINT;
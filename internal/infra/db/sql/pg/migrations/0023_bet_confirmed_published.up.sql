@@ -0,0 +1,15 @@
+-- 0023_bet_confirmed_published.up.sql
+-- bet_confirmed é publicado depois da transição PENDING_CONFIRMATION -> CONFIRMED|REJECTED.
+-- confirmed_published_at marca a publicação; decisões sem a marca (worker caiu entre a
+-- transição e a publicação) são republicadas pelo bet-confirmation-worker.
+-- provider_ref guarda a referência do fornecedor para a republicação.
+
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS provider_ref TEXT;
+ALTER TABLE bets ADD COLUMN IF NOT EXISTS confirmed_published_at TIMESTAMPTZ;
+
+-- decisões anteriores: consideradas publicadas
+UPDATE bets SET confirmed_published_at = updated_at
+WHERE status <> 'PENDING_CONFIRMATION' AND confirmed_published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bets_confirmed_unpublished
+  ON bets(updated_at) WHERE status IN ('CONFIRMED','REJECTED') AND confirmed_published_at IS NULL;
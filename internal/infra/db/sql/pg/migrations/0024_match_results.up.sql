@@ -0,0 +1,11 @@
+-- 0024_match_results.up.sql
+-- Resultados definitivos (FINISHED/CANCELLED) recebidos pelo bet-settlement-worker.
+-- O resultado é gravado antes da liquidação: se ela falhar depois das tentativas, o offset
+-- avança e a varredura periódica liquida as apostas a partir do resultado gravado.
+
+CREATE TABLE IF NOT EXISTS match_results (
+  event_id    TEXT PRIMARY KEY,
+  status      TEXT NOT NULL,
+  payload     JSONB NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
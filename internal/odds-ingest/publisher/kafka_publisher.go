@@ -112,6 +112,29 @@ func (p *KafkaPublisher) Publish(ctx context.Context, e events.OddsUpdate) error
 	return nil
 }
 
+// PublishMatchResult serializa o placar/resultado em JSON e envia para o tópico configurado.
+// A chave também é o EventID, preservando a ordem das mensagens de uma mesma partida.
+func (p *KafkaPublisher) PublishMatchResult(ctx context.Context, r events.MatchResult) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(r.EventID),
+		Value: value,
+		Time:  time.Now(),
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		p.log.Error("failed to publish match result", zap.Error(err))
+		return err
+	}
+
+	p.log.Debug("published match result", zap.String("event_id", r.EventID), zap.String("status", r.Status))
+	return nil
+}
+
 // Close finaliza o writer e libera recursos associados.
 func (p *KafkaPublisher) Close() error {
 	return p.writer.Close()
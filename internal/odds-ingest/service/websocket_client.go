@@ -16,9 +16,15 @@ import (
 // WSClient representa um cliente WebSocket responsável por consumir odds de um fornecedor
 // e publicar as atualizações recebidas em um tópico Kafka.
 type WSClient struct {
-	URL       string                    // URL do endpoint WebSocket do fornecedor
-	Log       *zap.Logger               // Logger estruturado
-	Publisher *publisher.KafkaPublisher // Publisher Kafka para envio das odds
+	URL             string                    // URL do endpoint WebSocket do fornecedor
+	Log             *zap.Logger               // Logger estruturado
+	Publisher       *publisher.KafkaPublisher // Publisher Kafka para envio das odds
+	ResultPublisher *publisher.KafkaPublisher // Publisher Kafka para placares/resultados (opcional)
+}
+
+// envelope identifica o tipo da mensagem; odds não carregam "type"
+type envelope struct {
+	Type string `json:"type"`
 }
 
 // Start inicia o loop de conexão e escuta do WebSocket.
@@ -58,6 +64,18 @@ func (c *WSClient) connectAndListen(ctx context.Context) error {
 			return err
 		}
 
+		var env envelope
+		if err := json.Unmarshal(message, &env); err != nil {
+			c.Log.Warn("invalid message", zap.Error(err))
+			continue
+		}
+
+		// Placares/resultados seguem para o tópico de resultados
+		if env.Type == events.MessageTypeMatchResult {
+			c.handleMatchResult(ctx, message)
+			continue
+		}
+
 		var update events.OddsUpdate
 		if err := json.Unmarshal(message, &update); err != nil {
 			c.Log.Warn("invalid message", zap.Error(err))
@@ -70,3 +88,18 @@ func (c *WSClient) connectAndListen(ctx context.Context) error {
 		}
 	}
 }
+
+// handleMatchResult desserializa um placar/resultado e publica no Kafka
+func (c *WSClient) handleMatchResult(ctx context.Context, message []byte) {
+	if c.ResultPublisher == nil {
+		return
+	}
+	var res events.MatchResult
+	if err := json.Unmarshal(message, &res); err != nil {
+		c.Log.Warn("invalid match result", zap.Error(err))
+		return
+	}
+	if err := c.ResultPublisher.PublishMatchResult(ctx, res); err != nil {
+		c.Log.Error("failed to publish match result to Kafka", zap.Error(err))
+	}
+}
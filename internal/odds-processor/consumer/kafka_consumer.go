@@ -8,22 +8,26 @@ import (
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
-	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/cache"
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/pricing"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/repository"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
 	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 )
 
-// Processor consome mensagens de odds do Kafka, faz cache e persiste no banco
+// Processor consome mensagens de odds do Kafka, aplica a margem da casa, persiste no banco e faz cache
 // Callbacks de métricas podem ser usadas para monitoramento de cada etapa
 type Processor struct {
 	Log    *zap.Logger
 	Reader *kafka.Reader
 	Repo   *repository.PostgresRepo
-	Cache  *cache.RedisCache
+	Cache  *oddscache.Cache
+	Pricer *pricing.Pricer // margem da casa aplicada antes de gravar, cachear e notificar
 
 	OnConsumed     func()       // métricas (counter++)
 	OnCached       func()       // métricas
 	OnPersist      func()       // métricas
+	OnDropped      func(string) // métricas por motivo (stale | duplicate)
+	OnPriced       func(market string, overround float64)
 	OnError        func(string) // métricas por fase
 	OnAfterPersist func(events.OddsUpdate)
 }
@@ -56,39 +60,51 @@ func (p *Processor) Run(ctx context.Context) error {
 			}
 			continue
 		}
+		// status ausente (produtores antigos) é publicado explicitamente como OPEN
+		ev.Status = ev.MarketStatus()
 
-		// Atualiza cache Redis com a odd atual
-		if err := p.Cache.SetCurrent(ctx, ev); err != nil {
-			p.Log.Warn("redis set failed", zap.Error(err))
-			if p.OnError != nil {
-				p.OnError("cache")
-			}
-			// não bloqueia persistência se falhar o cache
-		} else if p.OnCached != nil {
-			p.OnCached() // callback de métrica: cache atualizado
+		// Precificação: remove a margem do fornecedor e aplica a da casa;
+		// daqui em diante ev carrega as odds publicadas
+		priced := p.Pricer.Apply(ev)
+		ev = priced.OddsUpdate
+		if p.OnPriced != nil {
+			p.OnPriced(ev.Market, priced.Overround)
 		}
 
-		// Persiste/atualiza odd atual e histórico no Postgres
-		if err := p.Repo.UpsertCurrent(ctx, ev); err != nil {
-			p.Log.Warn("db upsert failed", zap.Error(err))
+		// Persiste snapshot e histórico; versões antigas ou repetidas são descartadas
+		// sem atualizar cache nem notificar os clientes
+		result, err := p.Repo.Save(ctx, priced)
+		if err != nil {
+			p.Log.Warn("db save failed", zap.Error(err))
 			if p.OnError != nil {
-				p.OnError("db_upsert")
+				p.OnError("db_save")
 			}
 			continue
 		}
-
-		if err := p.Repo.InsertHistory(ctx, ev); err != nil {
-			p.Log.Warn("db insert history failed", zap.Error(err))
-			if p.OnError != nil {
-				p.OnError("db_history")
+		if result != repository.SaveApplied {
+			p.Log.Debug("odds update dropped",
+				zap.String("event_id", ev.EventID), zap.String("market", ev.MarketKey()),
+				zap.Int64("version", ev.Version), zap.String("reason", result))
+			if p.OnDropped != nil {
+				p.OnDropped(result)
 			}
 			continue
 		}
-
 		if p.OnPersist != nil {
 			p.OnPersist() // callback de métrica: persistência concluída
 		}
 
+		// Atualiza cache Redis com a odd atual
+		if err := p.Cache.SetMarket(ctx, oddscache.FromOddsUpdate(ev)); err != nil {
+			p.Log.Warn("redis set failed", zap.Error(err))
+			if p.OnError != nil {
+				p.OnError("cache")
+			}
+			// não bloqueia a notificação se falhar o cache
+		} else if p.OnCached != nil {
+			p.OnCached() // callback de métrica: cache atualizado
+		}
+
 		// Notifica pós-persistência (broadcast p/ Redis/WS)
 		if p.OnAfterPersist != nil {
 			p.OnAfterPersist(ev)
@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Métodos de aplicação da margem da casa sobre as probabilidades justas
+const (
+	// MethodProportional multiplica todas as probabilidades justas por (1 + margem)
+	MethodProportional = "proportional"
+	// MethodMarginWeights soma margem/n à probabilidade de cada seleção: a margem relativa
+	// fica proporcional à odd (margin weights proportional to the odds), pesando mais nas zebras
+	MethodMarginWeights = "margin_weights"
+	// MethodRaw registra preços repassados sem margem (overround do fornecedor inválido)
+	MethodRaw = "raw"
+)
+
+// Limites das odds publicadas
+const (
+	minOdd = 1.01
+	maxOdd = 1000.0
+)
+
+// wildcard casa com qualquer modalidade, competição ou mercado numa regra
+const wildcard = "*"
+
+// Rule define a margem da casa para uma modalidade/competição/mercado ("*" = qualquer)
+type Rule struct {
+	Sport       string
+	Competition string
+	Market      string  // nome do mercado sem linha (ex: "over_under")
+	Margin      float64 // ex: 0.05 = 5% de overround publicado
+	Method      string
+}
+
+// specificity pontua a regra para que a mais específica prevaleça (mercado > competição > modalidade)
+func (r Rule) specificity() int {
+	n := 0
+	if r.Market != wildcard {
+		n += 4
+	}
+	if r.Competition != wildcard {
+		n += 2
+	}
+	if r.Sport != wildcard {
+		n++
+	}
+	return n
+}
+
+func (r Rule) matches(sport, competition, market string) bool {
+	return (r.Sport == wildcard || r.Sport == sport) &&
+		(r.Competition == wildcard || r.Competition == competition) &&
+		(r.Market == wildcard || r.Market == market)
+}
+
+// Pricer aplica a margem da casa aos preços do fornecedor
+type Pricer struct {
+	Default Rule
+	Rules   []Rule
+}
+
+// Priced é a atualização com preços publicados e os dados de auditoria do cálculo
+type Priced struct {
+	events.OddsUpdate                    // Selections com as odds publicadas
+	Raw               []events.Selection // odds originais do fornecedor
+	Overround         float64            // soma das probabilidades implícitas do fornecedor (ex: 1.05)
+	Margin            float64            // margem da casa aplicada
+	Method            string
+}
+
+// New cria o Pricer com a margem padrão e as regras específicas
+func New(margin float64, method string, rules []Rule) (*Pricer, error) {
+	if err := validate(margin, method); err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if err := validate(r.Margin, r.Method); err != nil {
+			return nil, fmt.Errorf("rule %s/%s/%s: %w", r.Sport, r.Competition, r.Market, err)
+		}
+	}
+	return &Pricer{
+		Default: Rule{Sport: wildcard, Competition: wildcard, Market: wildcard, Margin: margin, Method: method},
+		Rules:   rules,
+	}, nil
+}
+
+func validate(margin float64, method string) error {
+	if margin < 0 || margin >= 1 {
+		return fmt.Errorf("margin must be in [0, 1): %v", margin)
+	}
+	if method != MethodProportional && method != MethodMarginWeights {
+		return fmt.Errorf("unknown pricing method: %q", method)
+	}
+	return nil
+}
+
+// Rule retorna a regra mais específica para o mercado; sem regra usa o padrão
+func (p *Pricer) Rule(sport, competition, market string) Rule {
+	best, score := p.Default, -1
+	for _, r := range p.Rules {
+		if r.matches(sport, competition, market) && r.specificity() > score {
+			best, score = r, r.specificity()
+		}
+	}
+	return best
+}
+
+// Apply calcula o overround do fornecedor, remove a margem dele (probabilidades justas)
+// e publica as odds com a margem da casa da regra aplicável. Sem overround válido as odds do
+// fornecedor são repassadas e a auditoria registra margem 0 com o método MethodRaw
+func (p *Pricer) Apply(u events.OddsUpdate) Priced {
+	market, _ := events.SplitMarketKey(events.NormalizeMarket(u.MarketKey()))
+	rule := p.Rule(strings.ToLower(u.Sport), strings.ToLower(u.Competition), market)
+
+	out := Priced{
+		OddsUpdate: u,
+		Raw:        u.Selections,
+		Overround:  Overround(u.Selections),
+		Margin:     rule.Margin,
+		Method:     rule.Method,
+	}
+	if out.Overround <= 0 {
+		out.Margin, out.Method = 0, MethodRaw
+		return out
+	}
+
+	n := float64(len(u.Selections))
+	published := make([]events.Selection, 0, len(u.Selections))
+	for _, s := range u.Selections {
+		fair := 1 / s.Odd / out.Overround
+		var implied float64
+		switch rule.Method {
+		case MethodMarginWeights:
+			implied = fair + rule.Margin/n
+		default:
+			implied = fair * (1 + rule.Margin)
+		}
+		odd := math.Max(minOdd, math.Min(maxOdd, 1/implied))
+		published = append(published, events.Selection{Name: s.Name, Odd: math.Round(odd*100) / 100})
+	}
+	out.Selections = published
+	return out
+}
+
+// Overround retorna a soma das probabilidades implícitas das seleções (1.0 = sem margem);
+// zero se alguma odd é inválida
+func Overround(sel []events.Selection) float64 {
+	var sum float64
+	for _, s := range sel {
+		if s.Odd <= 1 {
+			return 0
+		}
+		sum += 1 / s.Odd
+	}
+	return sum
+}
+
+// ParseMargin lê a margem padrão (ex.: "0.05"); valor vazio ou inválido é erro para que a
+// configuração falhe na inicialização em vez de cair num padrão silencioso
+func ParseMargin(s string) (float64, error) {
+	m, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid margin %q: %w", s, err)
+	}
+	return m, nil
+}
+
+// ParseRules lê regras no formato "sport/competition/market=margin[:method]" separadas por ";"
+// (ex.: "football/*/correct_score=0.12:margin_weights;football/gauchao/*=0.06").
+// Sem método, a regra usa defaultMethod
+func ParseRules(s, defaultMethod string) ([]Rule, error) {
+	var out []Rule
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		scope, value, ok := strings.Cut(item, "=")
+		parts := strings.Split(strings.ToLower(strings.TrimSpace(scope)), "/")
+		if !ok || len(parts) != 3 {
+			return nil, fmt.Errorf("invalid pricing rule %q: want sport/competition/market=margin[:method]", item)
+		}
+		margin, method, _ := strings.Cut(strings.TrimSpace(value), ":")
+		m, err := strconv.ParseFloat(margin, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pricing rule %q: %w", item, err)
+		}
+		if method == "" {
+			method = defaultMethod
+		}
+		r := Rule{Sport: parts[0], Competition: parts[1], Market: parts[2], Margin: m, Method: strings.ToLower(method)}
+		for _, f := range []*string{&r.Sport, &r.Competition, &r.Market} {
+			if *f = strings.TrimSpace(*f); *f == "" {
+				*f = wildcard
+			}
+		}
+		if r.Market != wildcard {
+			r.Market = events.NormalizeMarket(r.Market)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,148 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+func sel(odds ...float64) []events.Selection {
+	names := []string{"home", "draw", "away"}
+	if len(odds) == 2 {
+		names = []string{"over", "under"}
+	}
+	out := make([]events.Selection, len(odds))
+	for i, o := range odds {
+		out[i] = events.Selection{Name: names[i], Odd: o}
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		margin     float64
+		method     string
+		market     string
+		line       string
+		raw        []events.Selection
+		want       []float64
+		wantMargin float64
+		wantMethod string
+	}{
+		{
+			name: "proportional 1x2", margin: 0.05, method: MethodProportional, market: "1x2",
+			raw: sel(2.0, 3.5, 4.0), want: []float64{1.97, 3.45, 3.95},
+			wantMargin: 0.05, wantMethod: MethodProportional,
+		},
+		{
+			name: "margin_weights 1x2", margin: 0.05, method: MethodMarginWeights, market: "1x2",
+			raw: sel(2.0, 3.5, 4.0), want: []float64{2.00, 3.42, 3.88},
+			wantMargin: 0.05, wantMethod: MethodMarginWeights,
+		},
+		{
+			name: "proportional favourite and outsider", margin: 0.10, method: MethodProportional, market: "1x2",
+			raw: sel(1.25, 6.0, 15.0), want: []float64{1.17, 5.64, 14.09},
+			wantMargin: 0.10, wantMethod: MethodProportional,
+		},
+		{
+			// a margem pesa mais na zebra e quase não mexe no favorito
+			name: "margin_weights favourite and outsider", margin: 0.10, method: MethodMarginWeights, market: "1x2",
+			raw: sel(1.25, 6.0, 15.0), want: []float64{1.24, 5.14, 10.22},
+			wantMargin: 0.10, wantMethod: MethodMarginWeights,
+		},
+		{
+			// com seleções equiprováveis os dois métodos coincidem
+			name: "balanced two-way", margin: 0.08, method: MethodMarginWeights, market: "over_under", line: "2.5",
+			raw: sel(1.9, 1.9), want: []float64{1.85, 1.85},
+			wantMargin: 0.08, wantMethod: MethodMarginWeights,
+		},
+		{
+			name: "raw pass-through on invalid odd", margin: 0.05, method: MethodProportional, market: "1x2",
+			raw: sel(1.0, 3.5, 4.0), want: []float64{1.0, 3.5, 4.0},
+			wantMargin: 0, wantMethod: MethodRaw,
+		},
+		{
+			name: "raw pass-through on missing odd", margin: 0.05, method: MethodMarginWeights, market: "1x2",
+			raw: sel(2.0, 0, 4.0), want: []float64{2.0, 0, 4.0},
+			wantMargin: 0, wantMethod: MethodRaw,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.margin, tt.method, nil)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got := p.Apply(events.OddsUpdate{EventID: "E1", Market: tt.market, Line: tt.line, Selections: tt.raw})
+			if len(got.Selections) != len(tt.want) {
+				t.Fatalf("selections = %v, want %v", got.Selections, tt.want)
+			}
+			for i, s := range got.Selections {
+				if math.Abs(s.Odd-tt.want[i]) > 1e-9 {
+					t.Errorf("%s odd = %v, want %v", s.Name, s.Odd, tt.want[i])
+				}
+			}
+			if got.Margin != tt.wantMargin || got.Method != tt.wantMethod {
+				t.Errorf("margin/method = %v/%s, want %v/%s", got.Margin, got.Method, tt.wantMargin, tt.wantMethod)
+			}
+			if len(got.Raw) != len(tt.raw) || got.Raw[0] != tt.raw[0] {
+				t.Errorf("raw = %v, want %v", got.Raw, tt.raw)
+			}
+		})
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	rules, err := ParseRules("football/*/correct_score=0.12:margin_weights;football/gauchao/*=0.06", MethodProportional)
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+	p, err := New(0.05, MethodProportional, rules)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		sport, competition string
+		market             string
+		wantMargin         float64
+		wantMethod         string
+	}{
+		{"default", "basketball", "nba", "1x2", 0.05, MethodProportional},
+		{"competition rule", "football", "gauchao", "1x2", 0.06, MethodProportional},
+		{"market beats competition", "football", "gauchao", "correct_score", 0.12, MethodMarginWeights},
+		{"case-insensitive scope", "Football", "GAUCHAO", "1x2", 0.06, MethodProportional},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Apply(events.OddsUpdate{Sport: tt.sport, Competition: tt.competition, Market: tt.market, Selections: sel(2.0, 3.5, 4.0)})
+			if got.Margin != tt.wantMargin || got.Method != tt.wantMethod {
+				t.Errorf("margin/method = %v/%s, want %v/%s", got.Margin, got.Method, tt.wantMargin, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestParseMargin(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"0.05", 0.05, false},
+		{" 0.1 ", 0.1, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"5%", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMargin(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMargin(%q) = %v, %v; want %v, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
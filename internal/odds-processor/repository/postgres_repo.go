@@ -3,7 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-processor/pricing"
 	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 )
 
@@ -18,42 +22,122 @@ func NewPostgresRepo(db *sql.DB) *PostgresRepo {
 	return &PostgresRepo{DB: db}
 }
 
-// UpsertCurrent insere ou atualiza a odd corrente de um evento na tabela odds_current
-// Utiliza ON CONFLICT para garantir atomicidade e evitar duplicidade por event_id
-func (r *PostgresRepo) UpsertCurrent(ctx context.Context, e events.OddsUpdate) error {
+// Resultado da gravação de uma atualização de odds
+const (
+	SaveApplied   = "applied"   // versão nova: snapshot e histórico gravados
+	SaveStale     = "stale"     // versão anterior à gravada (fora de ordem): descartada
+	SaveDuplicate = "duplicate" // mesma versão já gravada (reentrega): descartada
+)
+
+// Save grava o snapshot corrente (odds publicadas) e o histórico do mercado (odds publicadas
+// e do fornecedor) numa única transação. O snapshot só é sobrescrito por versões mais novas;
+// atualizações antigas ou repetidas não alteram nada e são reportadas como SaveStale/SaveDuplicate
+func (r *PostgresRepo) Save(ctx context.Context, p pricing.Priced) (string, error) {
+	e := p.OddsUpdate
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	applied, err := upsertCurrent(ctx, tx, e)
+	if err != nil {
+		return "", err
+	}
+	if !applied {
+		var stored int64
+		market, line := events.SplitMarketKey(e.MarketKey())
+		if err := tx.QueryRowContext(ctx,
+			`SELECT version FROM odds_current WHERE event_id=$1 AND market=$2 AND line=$3`,
+			e.EventID, market, line,
+		).Scan(&stored); err != nil {
+			return "", err
+		}
+		if stored == e.Version {
+			return SaveDuplicate, nil
+		}
+		return SaveStale, nil
+	}
+
+	if err := insertHistory(ctx, tx, p); err != nil {
+		return "", err
+	}
+	return SaveApplied, tx.Commit()
+}
+
+// upsertCurrent insere ou atualiza o preço corrente de um mercado na tabela odds_current.
+// Utiliza ON CONFLICT por (event_id, market, line) e só sobrescreve versões mais antigas;
+// retorna false quando a linha gravada já tem versão igual ou mais nova
+func upsertCurrent(ctx context.Context, tx *sql.Tx, e events.OddsUpdate) (bool, error) {
 	const q = `
 		INSERT INTO odds_current
-		  (event_id, home_team, away_team, market, home_odd, draw_odd, away_odd, version, updated_at)
+		  (event_id, sport, competition, home_team, away_team, market, line, selections, status, version, updated_at)
 		VALUES
-		  ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-		ON CONFLICT (event_id) DO UPDATE SET
-		  home_team = EXCLUDED.home_team,
-		  away_team = EXCLUDED.away_team,
-		  market    = EXCLUDED.market,
-		  home_odd  = EXCLUDED.home_odd,
-		  draw_odd  = EXCLUDED.draw_odd,
-		  away_odd  = EXCLUDED.away_odd,
-		  version   = EXCLUDED.version,
-		  updated_at= EXCLUDED.updated_at
+		  ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (event_id, market, line) DO UPDATE SET
+		  sport       = EXCLUDED.sport,
+		  competition = EXCLUDED.competition,
+		  home_team  = EXCLUDED.home_team,
+		  away_team  = EXCLUDED.away_team,
+		  selections = EXCLUDED.selections,
+		  status     = EXCLUDED.status,
+		  version    = EXCLUDED.version,
+		  updated_at = EXCLUDED.updated_at
+		WHERE odds_current.version < EXCLUDED.version
 	`
-	_, err := r.DB.ExecContext(ctx, q,
-		e.EventID, e.HomeTeam, e.AwayTeam, e.Market,
-		e.Odds.Home, e.Odds.Draw, e.Odds.Away,
-		e.Version, e.UpdatedAt,
+	sel, err := json.Marshal(e.Selections)
+	if err != nil {
+		return false, err
+	}
+	market, line := events.SplitMarketKey(e.MarketKey())
+	res, err := tx.ExecContext(ctx, q,
+		e.EventID, e.Sport, e.Competition, e.HomeTeam, e.AwayTeam, market, line,
+		string(sel), e.MarketStatus(), e.Version, e.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
-// InsertHistory insere uma nova odd no histórico de odds (odds_history)
-func (r *PostgresRepo) InsertHistory(ctx context.Context, e events.OddsUpdate) error {
-	const q = `
-		INSERT INTO odds_history
-		  (event_id, home_odd, draw_odd, away_odd, version, updated_at)
-		VALUES
-		  ($1,$2,$3,$4,$5,$6)
-	`
-	_, err := r.DB.ExecContext(ctx, q,
-		e.EventID, e.Odds.Home, e.Odds.Draw, e.Odds.Away, e.Version, e.UpdatedAt,
+// insertHistory insere o preço publicado e o do fornecedor de cada seleção do mercado no
+// histórico de odds (odds_history), ignorando seleções já registradas para a mesma versão
+func insertHistory(ctx context.Context, tx *sql.Tx, p pricing.Priced) error {
+	e := p.OddsUpdate
+	if len(e.Selections) == 0 {
+		return nil
+	}
+	market, line := events.SplitMarketKey(e.MarketKey())
+
+	var (
+		b    strings.Builder
+		args []any
 	)
+	b.WriteString(`INSERT INTO odds_history
+		(event_id, market, line, selection, odd, raw_odd, raw_overround, margin, pricing_method, status, version, updated_at)
+		VALUES `)
+	for i, sel := range e.Selections {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		var raw any
+		if i < len(p.Raw) {
+			raw = p.Raw[i].Odd
+		}
+		n := len(args)
+		b.WriteString("(")
+		for j := 1; j <= 12; j++ {
+			if j > 1 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "$%d", n+j)
+		}
+		b.WriteString(")")
+		args = append(args, e.EventID, market, line, sel.Name, sel.Odd, raw, p.Overround, p.Margin, p.Method,
+			e.MarketStatus(), e.Version, e.UpdatedAt)
+	}
+	b.WriteString(` ON CONFLICT (event_id, market, line, selection, version) DO NOTHING`)
+	_, err := tx.ExecContext(ctx, b.String(), args...)
 	return err
 }
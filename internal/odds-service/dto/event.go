@@ -7,18 +7,71 @@ type Event struct {
 	AwayTeam string `json:"awayTeam"`
 }
 
-// Market representa um mercado de aposta (ex: resultado final)
+// Market representa um mercado de aposta de um evento (ex: resultado final, total de gols 2.5)
 type Market struct {
-	Market string `json:"market"`
+	Market    string `json:"market"`
+	Line      string `json:"line,omitempty"`
+	MarketKey string `json:"marketKey"` // valor a enviar no campo market da aposta (ex: "over_under:2.5")
+	Status    string `json:"status"`    // OPEN | SUSPENDED | CLOSED | SETTLED
+}
+
+// Selection representa uma seleção de um mercado com sua odd
+type Selection struct {
+	Name string  `json:"name"`
+	Odd  float64 `json:"odd"`
 }
 
 // Odds representa as odds de um mercado para um evento esportivo
 type Odds struct {
-	EventID   string  `json:"eventId"`
+	EventID    string      `json:"eventId"`
+	Market     string      `json:"market"`
+	Line       string      `json:"line,omitempty"`
+	MarketKey  string      `json:"marketKey"`
+	Selections []Selection `json:"selections"`
+	Status     string      `json:"status"` // apostas só são aceitas em mercados OPEN
+	Version    int64       `json:"version"`
+	UpdatedAt  string      `json:"updatedAt"`
+}
+
+// PricePoint é um preço publicado de uma seleção no histórico
+type PricePoint struct {
 	Market    string  `json:"market"`
-	HomeOdd   float64 `json:"homeOdd"`
-	DrawOdd   float64 `json:"drawOdd"`
-	AwayOdd   float64 `json:"awayOdd"`
-	Version   int     `json:"version"`
-	UpdatedAt string  `json:"updatedAt"`
+	Line      string  `json:"line,omitempty"`
+	MarketKey string  `json:"marketKey"`
+	Selection string  `json:"selection"`
+	Odd       float64 `json:"odd"`
+	Status    string  `json:"status"`
+	Version   int64   `json:"version"`
+	At        string  `json:"at"`
+}
+
+// HistoryResponse é uma página do histórico de preços de um evento
+type HistoryResponse struct {
+	EventID    string       `json:"eventId"`
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Points     []PricePoint `json:"points"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// Candle agrega os preços de uma seleção num intervalo (open/high/low/close)
+type Candle struct {
+	MarketKey string  `json:"marketKey"`
+	Selection string  `json:"selection"`
+	Start     string  `json:"start"` // início do intervalo
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Ticks     int     `json:"ticks"` // quantidade de preços no intervalo
+}
+
+// CandlesResponse é uma página de candles OHLC de um evento
+type CandlesResponse struct {
+	EventID    string   `json:"eventId"`
+	Interval   string   `json:"interval"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Candles    []Candle `json:"candles"`
+	NextCursor string   `json:"nextCursor,omitempty"`
 }
@@ -3,28 +3,33 @@ package httpapi
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/cache"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/dto"
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 )
 
 // API expõe os endpoints REST de consulta de odds esportivas
 // Utiliza um repositório de leitura (Postgres) e cache (Redis)
 type API struct {
-	ReadRepo *repo.ReadRepo // acesso ao banco de dados
-	Cache    *cache.Cache   // cache de odds
+	ReadRepo *repo.ReadRepo   // acesso ao banco de dados
+	Cache    *oddscache.Cache // cache de odds compartilhado (gravado pelo odds-processor)
 }
 
 // Router retorna o roteador HTTP com os endpoints REST
 func (a *API) Router() http.Handler {
 	r := chi.NewRouter()
-	r.Get("/v1/events", a.listEvents)               // Lista eventos esportivos
-	r.Get("/v1/events/{id}/markets", a.listMarkets) // Lista mercados de um evento
-	r.Get("/v1/events/{id}/odds", a.getOdds)        // Lista odds de um evento
+	r.Get("/v1/events", a.listEvents)                // Lista eventos esportivos
+	r.Get("/v1/events/{id}/markets", a.listMarkets)  // Lista mercados de um evento
+	r.Get("/v1/events/{id}/odds", a.getOdds)         // Lista odds de um evento
+	r.Get("/v1/events/{id}/history", a.getHistory)   // Histórico de preços (ticks)
+	r.Get("/v1/events/{id}/history/ohlc", a.getOHLC) // Histórico agregado em candles OHLC
 	return r
 }
 
@@ -56,13 +61,17 @@ func (a *API) listMarkets(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, mk)
 }
 
-// getOdds retorna as odds de um evento, preferencialmente do cache
+// getOdds retorna as odds de um evento, preferencialmente do cache compartilhado;
+// o banco é usado quando o evento não está em cache (ex.: sem atualizações recentes)
 func (a *API) getOdds(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	var fromCache []dto.Odds
-	if ok, _ := a.Cache.GetOdds(r.Context(), id, &fromCache); ok {
-		writeJSON(w, http.StatusOK, fromCache)
+	if snaps, err := a.Cache.Event(r.Context(), id); err == nil {
+		out := make([]dto.Odds, 0, len(snaps))
+		for _, s := range snaps {
+			out = append(out, toOdds(s))
+		}
+		writeJSON(w, http.StatusOK, out)
 		return
 	}
 
@@ -76,6 +85,138 @@ func (a *API) getOdds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = a.Cache.SetOdds(r.Context(), id, od, 30*time.Second) // salva no cache por 30s
 	writeJSON(w, http.StatusOK, od)
 }
+
+// maxHistoryRange limita o intervalo de tempo consultável no histórico
+const maxHistoryRange = 31 * 24 * time.Hour
+
+// getHistory retorna os preços publicados de um evento (ou de um mercado/seleção) no intervalo
+// query: market, selection, from, to (RFC3339; padrão: última hora), limit, cursor
+func (a *API) getHistory(w http.ResponseWriter, r *http.Request) {
+	f, ok := historyFilter(w, r, time.Hour)
+	if !ok {
+		return
+	}
+	points, next, err := a.ReadRepo.ListHistory(r.Context(), f)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+	if points == nil {
+		points = []dto.PricePoint{}
+	}
+	writeJSON(w, http.StatusOK, dto.HistoryResponse{
+		EventID:    f.EventID,
+		From:       f.From.Format(time.RFC3339),
+		To:         f.To.Format(time.RFC3339),
+		Points:     points,
+		NextCursor: next,
+	})
+}
+
+// getOHLC retorna candles open/high/low/close por seleção
+// query: interval (1m | 5m | 1h), market, selection, from, to (padrão: últimos 60 intervalos), limit, cursor
+func (a *API) getOHLC(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("interval")
+	if name == "" {
+		name = "1m"
+	}
+	interval, ok := repo.Intervals[name]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "interval must be one of 1m, 5m, 1h"})
+		return
+	}
+	f, ok := historyFilter(w, r, 60*interval)
+	if !ok {
+		return
+	}
+	candles, next, err := a.ReadRepo.ListCandles(r.Context(), f, interval)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+	if candles == nil {
+		candles = []dto.Candle{}
+	}
+	writeJSON(w, http.StatusOK, dto.CandlesResponse{
+		EventID:    f.EventID,
+		Interval:   name,
+		From:       f.From.Format(time.RFC3339),
+		To:         f.To.Format(time.RFC3339),
+		Candles:    candles,
+		NextCursor: next,
+	})
+}
+
+// historyFilter lê os filtros comuns do histórico; em caso de erro responde 400 e retorna ok=false
+func historyFilter(w http.ResponseWriter, r *http.Request, defaultRange time.Duration) (repo.HistoryFilter, bool) {
+	q := r.URL.Query()
+	f := repo.HistoryFilter{
+		EventID:   chi.URLParam(r, "id"),
+		Market:    q.Get("market"),
+		Selection: q.Get("selection"),
+		Cursor:    q.Get("cursor"),
+		To:        time.Now().UTC(),
+	}
+	bad := func(msg string) (repo.HistoryFilter, bool) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return repo.HistoryFilter{}, false
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return bad("to must be RFC3339")
+		}
+		f.To = t.UTC()
+	}
+	f.From = f.To.Add(-defaultRange)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return bad("from must be RFC3339")
+		}
+		f.From = t.UTC()
+	}
+	if !f.From.Before(f.To) {
+		return bad("from must be before to")
+	}
+	if f.To.Sub(f.From) > maxHistoryRange {
+		return bad("time range must be at most 31 days")
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return bad("limit must be a positive integer")
+		}
+		f.Limit = n
+	}
+	return f, true
+}
+
+// writeHistoryError responde 400 para cursor inválido e 500 para os demais erros
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repo.ErrInvalidCursor) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
+
+// toOdds converte o snapshot do cache no formato de resposta da API
+func toOdds(s oddscache.MarketSnapshot) dto.Odds {
+	sel := make([]dto.Selection, 0, len(s.Selections))
+	for _, x := range s.Selections {
+		sel = append(sel, dto.Selection{Name: x.Name, Odd: x.Odd})
+	}
+	return dto.Odds{
+		EventID:    s.EventID,
+		Market:     s.Market,
+		Line:       s.Line,
+		MarketKey:  s.Key(),
+		Selections: sel,
+		Status:     events.NormalizeMarketStatus(s.Status),
+		Version:    s.Version,
+		UpdatedAt:  s.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
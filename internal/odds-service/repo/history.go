@@ -0,0 +1,213 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/dto"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Limites de página do histórico de preços
+const (
+	DefaultHistoryPageSize = 500
+	MaxHistoryPageSize     = 5000
+)
+
+// Intervals são os intervalos aceitos para candles OHLC
+var Intervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+}
+
+// ErrInvalidCursor indica um cursor de paginação malformado
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HistoryFilter define a consulta ao histórico de um evento no intervalo [From, To).
+// Market (marketKey, ex: "over_under:2.5") e Selection vazios não filtram.
+// Cursor é o valor opaco retornado como NextCursor na página anterior
+type HistoryFilter struct {
+	EventID   string
+	Market    string
+	Selection string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Cursor    string
+}
+
+// where monta as condições comuns do filtro a partir de $1
+func (f HistoryFilter) where() ([]string, []any) {
+	where := []string{"event_id = $1", "updated_at >= $2", "updated_at < $3"}
+	args := []any{f.EventID, f.From, f.To}
+	if f.Market != "" {
+		market, line := events.SplitMarketKey(events.NormalizeMarket(f.Market))
+		args = append(args, market, line)
+		where = append(where, fmt.Sprintf("market = $%d AND line = $%d", len(args)-1, len(args)))
+	}
+	if f.Selection != "" {
+		args = append(args, events.NormalizeSelection(f.Selection))
+		where = append(where, fmt.Sprintf("selection = $%d", len(args)))
+	}
+	return where, args
+}
+
+func (f *HistoryFilter) clampLimit() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultHistoryPageSize
+	}
+	if f.Limit > MaxHistoryPageSize {
+		f.Limit = MaxHistoryPageSize
+	}
+}
+
+// ListHistory retorna uma página dos preços publicados do evento em ordem cronológica
+// e o cursor da próxima página (vazio na última)
+func (r *ReadRepo) ListHistory(ctx context.Context, f HistoryFilter) ([]dto.PricePoint, string, error) {
+	f.clampLimit()
+	where, args := f.where()
+	if f.Cursor != "" {
+		parts, err := decodeCursor(f.Cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		ts, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		args = append(args, ts, id)
+		where = append(where, fmt.Sprintf("(updated_at, id) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, f.Limit+1)
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, market, line, selection, odd, status, version, updated_at
+		FROM odds_history
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY updated_at, id
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		out    []dto.PricePoint
+		lastID int64
+		lastAt time.Time
+	)
+	for rows.Next() {
+		if len(out) == f.Limit {
+			// linha extra: existe próxima página
+			return out, encodeCursor(lastAt.UTC().Format(time.RFC3339Nano), strconv.FormatInt(lastID, 10)), rows.Err()
+		}
+		var p dto.PricePoint
+		if err := rows.Scan(&lastID, &p.Market, &p.Line, &p.Selection, &p.Odd, &p.Status, &p.Version, &lastAt); err != nil {
+			return nil, "", err
+		}
+		p.MarketKey = events.MarketKey(p.Market, p.Line)
+		p.At = lastAt.UTC().Format(time.RFC3339Nano)
+		out = append(out, p)
+	}
+	return out, "", rows.Err()
+}
+
+// ListCandles agrega os preços publicados do evento em candles OHLC por seleção e intervalo,
+// em ordem de (início do intervalo, mercado, linha, seleção), e retorna o cursor da próxima página
+func (r *ReadRepo) ListCandles(ctx context.Context, f HistoryFilter, interval time.Duration) ([]dto.Candle, string, error) {
+	f.clampLimit()
+	where, args := f.where()
+	args = append(args, interval.Seconds())
+	secs := len(args)
+
+	having := ""
+	if f.Cursor != "" {
+		parts, err := decodeCursor(f.Cursor, 4)
+		if err != nil {
+			return nil, "", err
+		}
+		start, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		// o intervalo do cursor pode ter seleções restantes: filtra a partir dele e desempata no HAVING
+		args = append(args, start)
+		where = append(where, fmt.Sprintf("updated_at >= $%d", len(args)))
+		args = append(args, start, parts[1], parts[2], parts[3])
+		having = fmt.Sprintf("HAVING (bucket, market, line, selection) > ($%d, $%d, $%d, $%d)",
+			len(args)-3, len(args)-2, len(args)-1, len(args))
+	}
+	args = append(args, f.Limit+1)
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT bucket, market, line, selection,
+		       (array_agg(odd ORDER BY updated_at, id))[1]           AS open,
+		       MAX(odd)                                               AS high,
+		       MIN(odd)                                               AS low,
+		       (array_agg(odd ORDER BY updated_at DESC, id DESC))[1] AS close,
+		       COUNT(*)                                               AS ticks
+		FROM (
+		  SELECT id, market, line, selection, odd, updated_at,
+		         to_timestamp(floor(extract(epoch FROM updated_at) / $`+fmt.Sprint(secs)+`) * $`+fmt.Sprint(secs)+`) AS bucket
+		  FROM odds_history
+		  WHERE `+strings.Join(where, " AND ")+`
+		) h
+		GROUP BY bucket, market, line, selection
+		`+having+`
+		ORDER BY bucket, market, line, selection
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		out  []dto.Candle
+		last [4]string
+	)
+	for rows.Next() {
+		if len(out) == f.Limit {
+			return out, encodeCursor(last[:]...), rows.Err()
+		}
+		var (
+			c            dto.Candle
+			start        time.Time
+			market, line string
+		)
+		if err := rows.Scan(&start, &market, &line, &c.Selection, &c.Open, &c.High, &c.Low, &c.Close, &c.Ticks); err != nil {
+			return nil, "", err
+		}
+		c.MarketKey = events.MarketKey(market, line)
+		c.Start = start.UTC().Format(time.RFC3339)
+		last = [4]string{c.Start, market, line, c.Selection}
+		out = append(out, c)
+	}
+	return out, "", rows.Err()
+}
+
+// encodeCursor gera o cursor opaco a partir das chaves do último item da página
+func encodeCursor(keys ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(keys, "|")))
+}
+
+func decodeCursor(c string, n int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", n)
+	if len(parts) != n {
+		return nil, ErrInvalidCursor
+	}
+	return parts, nil
+}
@@ -0,0 +1,298 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/dto"
+)
+
+// fakeDB simula o Postgres para as consultas paginadas do histórico: aplica o cursor
+// (comparação de tupla) e o LIMIT sobre linhas já ordenadas como o ORDER BY das consultas
+type fakeDB struct {
+	candles []candleRow
+	points  []pointRow
+}
+
+type candleRow struct {
+	bucket                  time.Time
+	market, line, selection string
+	open, high, low, close  float64
+	ticks                   int64
+}
+
+type pointRow struct {
+	id                              int64
+	market, line, selection, status string
+	odd                             float64
+	version                         int64
+	at                              time.Time
+}
+
+// candleArgs e historyArgs: event_id, from, to (+ segundos do intervalo nos candles)
+const (
+	candleArgs  = 4
+	historyArgs = 3
+)
+
+func (f *fakeDB) query(q string, args []driver.Value) (driver.Rows, error) {
+	limit := int(args[len(args)-1].(int64))
+	if strings.Contains(q, "GROUP BY bucket") {
+		var cur []driver.Value
+		if len(args) > candleArgs+1 {
+			// updated_at >= início do cursor e a tupla do HAVING
+			if !args[candleArgs].(time.Time).Equal(args[candleArgs+1].(time.Time)) {
+				return nil, errors.New("cursor start mismatch")
+			}
+			cur = args[candleArgs+1 : candleArgs+5]
+		}
+		rows := &fakeRows{cols: []string{"bucket", "market", "line", "selection", "open", "high", "low", "close", "ticks"}}
+		for _, c := range f.candles {
+			if cur != nil && !candleAfter(c, cur) {
+				continue
+			}
+			if len(rows.data) == limit {
+				break
+			}
+			rows.data = append(rows.data, []driver.Value{c.bucket, c.market, c.line, c.selection, c.open, c.high, c.low, c.close, c.ticks})
+		}
+		return rows, nil
+	}
+
+	var cur []driver.Value
+	if len(args) > historyArgs+1 {
+		cur = args[historyArgs : historyArgs+2]
+	}
+	rows := &fakeRows{cols: []string{"id", "market", "line", "selection", "odd", "status", "version", "updated_at"}}
+	for _, p := range f.points {
+		if cur != nil {
+			at, id := cur[0].(time.Time), cur[1].(int64)
+			if p.at.Before(at) || (p.at.Equal(at) && p.id <= id) {
+				continue
+			}
+		}
+		if len(rows.data) == limit {
+			break
+		}
+		rows.data = append(rows.data, []driver.Value{p.id, p.market, p.line, p.selection, p.odd, p.status, p.version, p.at})
+	}
+	return rows, nil
+}
+
+// candleAfter compara (bucket, market, line, selection) > cursor como o HAVING da consulta
+func candleAfter(c candleRow, cur []driver.Value) bool {
+	start := cur[0].(time.Time)
+	switch {
+	case !c.bucket.Equal(start):
+		return c.bucket.After(start)
+	case c.market != cur[1].(string):
+		return c.market > cur[1].(string)
+	case c.line != cur[2].(string):
+		return c.line > cur[2].(string)
+	}
+	return c.selection > cur[3].(string)
+}
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(q string) (driver.Stmt, error) { return fakeStmt{db: c.db, q: q}, nil }
+func (c fakeConn) Close() error                          { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)             { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	db *fakeDB
+	q  string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.db.query(s.q, args)
+}
+
+type fakeRows struct {
+	cols []string
+	data [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
+
+var (
+	fakeMu  sync.Mutex
+	fakeSeq int
+)
+
+func newFakeRepo(t *testing.T, f *fakeDB) *ReadRepo {
+	t.Helper()
+	fakeMu.Lock()
+	fakeSeq++
+	name := fmt.Sprintf("fake-history-%d", fakeSeq)
+	fakeMu.Unlock()
+	sql.Register(name, fakeDriver{db: f})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &ReadRepo{DB: db}
+}
+
+func fixtureCandles() []candleRow {
+	t0 := time.Date(2025, 11, 9, 20, 0, 0, 0, time.UTC)
+	var out []candleRow
+	for i := 0; i < 3; i++ {
+		b := t0.Add(time.Duration(i) * time.Minute)
+		for _, k := range [][3]string{
+			{"1x2", "", "away"}, {"1x2", "", "draw"}, {"1x2", "", "home"},
+			{"over_under", "2.5", "over"}, {"over_under", "2.5", "under"},
+		} {
+			out = append(out, candleRow{bucket: b, market: k[0], line: k[1], selection: k[2], open: 2, high: 2.1, low: 1.9, close: 2.05, ticks: 3})
+		}
+	}
+	return out
+}
+
+func TestListCandlesPaging(t *testing.T) {
+	all := fixtureCandles()
+	tests := []struct {
+		name      string
+		limit     int
+		wantPages int
+	}{
+		{"page smaller than a bucket", 2, 8},
+		{"page crossing buckets", 4, 4},
+		{"page equal to a bucket", 5, 3},
+		{"exact fit", 15, 1},
+		{"single page", 100, 1},
+		{"one per page", 1, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(t, &fakeDB{candles: all})
+			f := HistoryFilter{EventID: "E1", From: all[0].bucket, To: all[len(all)-1].bucket.Add(time.Minute), Limit: tt.limit}
+
+			var got []dto.Candle
+			pages := 0
+			for {
+				page, next, err := r.ListCandles(context.Background(), f, time.Minute)
+				if err != nil {
+					t.Fatalf("page %d: %v", pages+1, err)
+				}
+				pages++
+				if len(page) > tt.limit || (next != "" && len(page) != tt.limit) {
+					t.Fatalf("page %d: %d candles with next=%q (limit %d)", pages, len(page), next, tt.limit)
+				}
+				got = append(got, page...)
+				if next == "" {
+					break
+				}
+				if pages > len(all) {
+					t.Fatal("cursor does not advance")
+				}
+				f.Cursor = next
+			}
+
+			if pages != tt.wantPages {
+				t.Errorf("pages = %d, want %d", pages, tt.wantPages)
+			}
+			if len(got) != len(all) {
+				t.Fatalf("candles = %d, want %d", len(got), len(all))
+			}
+			for i, c := range got {
+				w := all[i]
+				if c.Start != w.bucket.Format(time.RFC3339) || c.Selection != w.selection || c.Ticks != int(w.ticks) {
+					t.Fatalf("candle %d = %+v, want %s %s/%s", i, c, w.bucket.Format(time.RFC3339), w.market, w.selection)
+				}
+			}
+		})
+	}
+}
+
+func TestListHistoryPaging(t *testing.T) {
+	at := time.Date(2025, 11, 9, 20, 0, 0, 0, time.UTC)
+	var all []pointRow
+	for i := int64(1); i <= 7; i++ {
+		// pares de preços no mesmo instante: o id desempata a ordem
+		all = append(all, pointRow{id: i, market: "1x2", selection: "home", status: "OPEN", odd: 2, version: i, at: at.Add(time.Duration(i/2) * time.Second)})
+	}
+	for _, limit := range []int{1, 2, 3, 7, 10} {
+		r := newFakeRepo(t, &fakeDB{points: all})
+		f := HistoryFilter{EventID: "E1", From: at, To: at.Add(time.Hour), Limit: limit}
+		var ids []int64
+		for {
+			page, next, err := r.ListHistory(context.Background(), f)
+			if err != nil {
+				t.Fatalf("limit %d: %v", limit, err)
+			}
+			for _, p := range page {
+				ids = append(ids, p.Version)
+			}
+			if next == "" {
+				break
+			}
+			if len(ids) > len(all) {
+				t.Fatalf("limit %d: cursor does not advance", limit)
+			}
+			f.Cursor = next
+		}
+		if len(ids) != len(all) {
+			t.Fatalf("limit %d: got %v", limit, ids)
+		}
+		for i, id := range ids {
+			if id != int64(i+1) {
+				t.Fatalf("limit %d: got %v", limit, ids)
+			}
+		}
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	r := newFakeRepo(t, &fakeDB{})
+	f := HistoryFilter{EventID: "E1", From: time.Now().Add(-time.Hour), To: time.Now()}
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"too few keys", encodeCursor("2025-11-09T20:00:00Z", "1x2")},
+		{"bad start", encodeCursor("yesterday", "1x2", "", "home")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.Cursor = tt.cursor
+			if _, _, err := r.ListCandles(context.Background(), f, time.Minute); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("ListCandles err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+	for _, c := range []string{"%%%", encodeCursor("2025-11-09T20:00:00Z"), encodeCursor("2025-11-09T20:00:00Z", "x")} {
+		f.Cursor = c
+		if _, _, err := r.ListHistory(context.Background(), f); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("ListHistory(%q) err = %v, want ErrInvalidCursor", c, err)
+		}
+	}
+}
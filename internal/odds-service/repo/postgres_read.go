@@ -3,8 +3,10 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/dto"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
 )
 
 // ReadRepo encapsula operações de leitura no banco de dados Postgres para odds
@@ -37,13 +39,13 @@ func (r *ReadRepo) ListEvents(ctx context.Context) ([]dto.Event, error) {
 	return out, rows.Err()
 }
 
-// ListMarkets retorna todos os mercados distintos de um evento
+// ListMarkets retorna todos os mercados (mercado + linha) de um evento
 func (r *ReadRepo) ListMarkets(ctx context.Context, eventID string) ([]dto.Market, error) {
 	const q = `
-		SELECT DISTINCT market
+		SELECT market, line, status
 		FROM odds_current
 		WHERE event_id = $1
-		ORDER BY market;
+		ORDER BY market, line;
 	`
 	rows, err := r.DB.QueryContext(ctx, q, eventID)
 	if err != nil {
@@ -53,21 +55,22 @@ func (r *ReadRepo) ListMarkets(ctx context.Context, eventID string) ([]dto.Marke
 	var out []dto.Market
 	for rows.Next() {
 		var m dto.Market
-		if err := rows.Scan(&m.Market); err != nil {
+		if err := rows.Scan(&m.Market, &m.Line, &m.Status); err != nil {
 			return nil, err
 		}
+		m.MarketKey = events.MarketKey(m.Market, m.Line)
 		out = append(out, m)
 	}
 	return out, rows.Err()
 }
 
-// GetOddsByEvent retorna todas as odds de um evento
+// GetOddsByEvent retorna as odds de todos os mercados de um evento
 func (r *ReadRepo) GetOddsByEvent(ctx context.Context, eventID string) ([]dto.Odds, error) {
 	const q = `
-		SELECT event_id, market, home_odd, draw_odd, away_odd, version, to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SSZ')
+		SELECT event_id, market, line, selections, status, version, to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SSZ')
 		FROM odds_current
 		WHERE event_id = $1
-		ORDER BY market;
+		ORDER BY market, line;
 	`
 	rows, err := r.DB.QueryContext(ctx, q, eventID)
 	if err != nil {
@@ -76,10 +79,17 @@ func (r *ReadRepo) GetOddsByEvent(ctx context.Context, eventID string) ([]dto.Od
 	defer rows.Close()
 	var out []dto.Odds
 	for rows.Next() {
-		var o dto.Odds
-		if err := rows.Scan(&o.EventID, &o.Market, &o.HomeOdd, &o.DrawOdd, &o.AwayOdd, &o.Version, &o.UpdatedAt); err != nil {
+		var (
+			o   dto.Odds
+			sel []byte
+		)
+		if err := rows.Scan(&o.EventID, &o.Market, &o.Line, &sel, &o.Status, &o.Version, &o.UpdatedAt); err != nil {
 			return nil, err
 		}
+		if err := json.Unmarshal(sel, &o.Selections); err != nil {
+			return nil, err
+		}
+		o.MarketKey = events.MarketKey(o.Market, o.Line)
 		out = append(out, o)
 	}
 	return out, rows.Err()
@@ -0,0 +1,150 @@
+package ws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Políticas aplicadas quando a fila de envio de um cliente lento enche
+const (
+	// PolicyDisconnect encerra a conexão do cliente lento (ele reconecta e recebe um novo snapshot)
+	PolicyDisconnect = "disconnect"
+	// PolicyDrop descarta os deltas que não cabem na fila e mantém a conexão;
+	// frames de controle (ex.: snapshot) que não cabem encerram a conexão
+	PolicyDrop = "drop"
+)
+
+// ParseSlowConsumerPolicy valida a política de cliente lento (case-insensitive); valores
+// desconhecidos são erro para que a configuração falhe na inicialização
+func ParseSlowConsumerPolicy(s string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(s)); p {
+	case PolicyDisconnect, PolicyDrop:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid slow consumer policy %q: want %s or %s", s, PolicyDisconnect, PolicyDrop)
+}
+
+// Motivos de encerramento de conexões pelo Hub
+const (
+	EvictQueueFull    = "queue_full"    // fila de envio cheia (PolicyDisconnect)
+	EvictWriteTimeout = "write_timeout" // escrita não concluída dentro do WriteTimeout
+)
+
+// Motivos de desconexão (inclui os de EvictQueueFull e EvictWriteTimeout)
+const (
+	DisconnectClientClosed = "client_closed" // close frame ou conexão encerrada pelo cliente
+	DisconnectPongTimeout  = "pong_timeout"  // nenhum pong/mensagem dentro do prazo de leitura
+	DisconnectIdle         = "idle"          // conexão sem inscrições além do IdleTimeout
+	DisconnectReadError    = "read_error"
+	DisconnectWriteError   = "write_error"
+)
+
+// transport é o meio pelo qual os frames chegam ao cliente (WebSocket ou SSE)
+type transport interface {
+	write(b []byte, deadline time.Time) error // envia um frame
+	ping(deadline time.Time) error            // envia o heartbeat
+	close() error
+}
+
+// wsConn entrega os frames como mensagens de texto WebSocket
+type wsConn struct{ *websocket.Conn }
+
+func (c wsConn) write(b []byte, deadline time.Time) error {
+	_ = c.SetWriteDeadline(deadline)
+	return c.WriteMessage(websocket.TextMessage, b)
+}
+
+func (c wsConn) ping(deadline time.Time) error {
+	return c.WriteControl(websocket.PingMessage, nil, deadline)
+}
+
+func (c wsConn) close() error { return c.Close() }
+
+// client é uma conexão (WebSocket ou SSE) com fila de saída própria. Somente a goroutine
+// de escrita (Hub.writeLoop) escreve na conexão
+type client struct {
+	conn   transport
+	sse    bool          // conexão Server-Sent Events (HandleSSE)
+	send   chan []byte   // frames prontos para envio (limitada)
+	done   chan struct{} // fechado no encerramento da conexão
+	once   sync.Once
+	reason string // motivo do encerramento (gravado uma vez em closeWith)
+
+	// heartbeat é o intervalo de ping negociado; mudanças são avisadas em reset
+	heartbeat atomic.Int64
+	reset     chan struct{}
+
+	// events e topics são as inscrições da conexão (protegidos por Hub.mu); idleSince
+	// marca desde quando a conexão está sem inscrições (0 = inscrita)
+	events    map[string]struct{}
+	topics    map[string]struct{}
+	idleSince atomic.Int64
+
+	// mu ordena os frames de um evento em inscrição: enquanto o snapshot é carregado,
+	// os deltas do evento ficam em pending e são enfileirados depois dele
+	mu      sync.Mutex
+	pending map[string][]pendingFrame
+}
+
+func newClient(conn transport, queueSize int, heartbeat time.Duration) *client {
+	c := &client{
+		conn:    conn,
+		send:    make(chan []byte, queueSize),
+		done:    make(chan struct{}),
+		reset:   make(chan struct{}, 1),
+		events:  make(map[string]struct{}),
+		topics:  make(map[string]struct{}),
+		pending: make(map[string][]pendingFrame),
+	}
+	c.heartbeat.Store(int64(heartbeat))
+	c.idleSince.Store(time.Now().UnixNano())
+	return c
+}
+
+// offer enfileira o frame sem bloquear; false se a fila está cheia
+func (c *client) offer(b []byte) bool {
+	select {
+	case c.send <- b:
+		return true
+	default:
+		return false
+	}
+}
+
+// free retorna quantos frames ainda cabem na fila de envio
+func (c *client) free() int {
+	return cap(c.send) - len(c.send)
+}
+
+// markIdle marca o início da inatividade se a conexão ficou sem inscrições; chamado com Hub.mu travado
+func (c *client) markIdle() {
+	if len(c.events) == 0 && len(c.topics) == 0 {
+		c.idleSince.CompareAndSwap(0, time.Now().UnixNano())
+	}
+}
+
+// setHeartbeat troca o intervalo de ping e avisa a goroutine de escrita
+func (c *client) setHeartbeat(d time.Duration) {
+	c.heartbeat.Store(int64(d))
+	select {
+	case c.reset <- struct{}{}:
+	default:
+	}
+}
+
+// closeWith encerra a conexão uma única vez registrando o motivo (true na chamada que encerrou);
+// a leitura em HandleWS falha e remove as assinaturas
+func (c *client) closeWith(reason string) (closed bool) {
+	c.once.Do(func() {
+		c.reason = reason
+		close(c.done)
+		_ = c.conn.close()
+		closed = true
+	})
+	return closed
+}
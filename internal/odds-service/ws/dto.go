@@ -1,15 +1,74 @@
 package ws
 
+import "encoding/json"
+
 // ClientMsg representa uma mensagem recebida do cliente WebSocket
-// Type: subscribe | unsubscribe | ping
-// EventID: obrigatório para subscribe/unsubscribe
+// Type: subscribe | unsubscribe | ping | heartbeat
+// subscribe/unsubscribe exigem eventId, eventIds e/ou topic
 type ClientMsg struct {
-	Type    string `json:"type"`    // subscribe | unsubscribe | ping
-	EventID string `json:"eventId"` // requerido em subscribe/unsubscribe
+	Type       string   `json:"type"`                 // subscribe | unsubscribe | ping | heartbeat
+	EventID    string   `json:"eventId"`              // um evento
+	EventIDs   []string `json:"eventIds,omitempty"`   // vários eventos numa mensagem
+	Topic      string   `json:"topic,omitempty"`      // all | sport:{sport} | competition:{competition} | event:{eventId}
+	FromSeq    int64    `json:"fromSeq,omitempty"`    // subscribe: última sequência recebida (retomada após reconexão)
+	IntervalMs int64    `json:"intervalMs,omitempty"` // heartbeat: intervalo de ping desejado
 }
 
+// Tipos de frame enviados pelo servidor
+const (
+	MsgOdds         = "odds"         // delta: atualização de um mercado
+	MsgSnapshot     = "snapshot"     // estado atual de todos os mercados do evento, enviado após subscribed
+	MsgSubscribed   = "subscribed"   // confirmação de subscribe
+	MsgUnsubscribed = "unsubscribed" // confirmação de unsubscribe
+	MsgError        = "error"
+	MsgPong         = "pong"
+	MsgHeartbeat    = "heartbeat" // intervalo de ping efetivo após a negociação
+	MsgResync       = "resync"    // estado do cliente descartado; um snapshot novo vem em seguida
+)
+
+// Motivos do resync
+const (
+	ResyncTooOld   = "seq_too_old"      // fromSeq anterior ao histórico mantido no stream
+	ResyncSeqReset = "seq_reset"        // sequência do evento reiniciada no servidor
+	ResyncGap      = "gap"              // atualizações perdidas que o stream não cobre mais
+	ResyncTooLarge = "replay_too_large" // lacuna coberta pelo stream, mas maior que a fila de envio
+)
+
+// Códigos dos frames de erro
+const (
+	ErrCodeInvalidMessage      = "invalid_message"      // JSON inválido
+	ErrCodeUnknownType         = "unknown_type"         // type não suportado
+	ErrCodeEventRequired       = "event_id_required"    // subscribe/unsubscribe sem eventId, eventIds ou topic
+	ErrCodeInvalidTopic        = "invalid_topic"        // topic fora dos formatos aceitos
+	ErrCodeSubscriptionLimit   = "subscription_limit"   // inscrições da conexão excederiam o limite
+	ErrCodeUnknownEvent        = "unknown_event"        // evento sem odds publicadas
+	ErrCodeSnapshotUnavailable = "snapshot_unavailable" // falha ao carregar o snapshot (inscrição mantida)
+)
+
 // OddsUpdate representa uma atualização de odds enviada para clientes WebSocket
+// (mesmo formato dos frames guardados no stream do evento)
 type OddsUpdate struct {
-	EventID string      `json:"eventId"`
-	Payload interface{} `json:"payload"`
+	Type    string          `json:"type,omitempty"` // "odds"
+	EventID string          `json:"eventId"`
+	Seq     int64           `json:"seq,omitempty"` // sequência do evento, crescente a cada atualização
+	Payload json.RawMessage `json:"payload"`
+
+	// roteamento para inscrições por tópico e status do mercado (lidos do payload, não reenviados)
+	Sport       string `json:"-"`
+	Competition string `json:"-"`
+	Status      string `json:"-"`
+}
+
+// ServerMsg representa um frame de controle enviado ao cliente
+// (confirmações, snapshot, resync, erros e pong)
+type ServerMsg struct {
+	Type       string      `json:"type"`
+	EventID    string      `json:"eventId,omitempty"`
+	Topic      string      `json:"topic,omitempty"`      // subscribed/unsubscribed de tópico
+	Seq        int64       `json:"seq,omitempty"`        // snapshot: última sequência coberta por ele
+	Reason     string      `json:"reason,omitempty"`     // resync: motivo (ex: seq_too_old)
+	Payload    interface{} `json:"payload,omitempty"`    // snapshot: lista de mercados no formato do payload dos deltas
+	Error      string      `json:"error,omitempty"`      // código do erro (ex: unknown_event)
+	Message    string      `json:"message,omitempty"`    // detalhe do erro
+	IntervalMs int64       `json:"intervalMs,omitempty"` // heartbeat: intervalo de ping efetivo
 }
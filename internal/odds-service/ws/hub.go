@@ -1,85 +1,459 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddsstream"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// snapshotTimeout limita a leitura do snapshot (cache/banco) na inscrição
+const snapshotTimeout = 3 * time.Second
+
+// Padrões da fila de envio por conexão
+const (
+	DefaultSendQueueSize = 256
+	DefaultWriteTimeout  = 5 * time.Second
+)
+
+// Padrões de heartbeat: o servidor envia ping (protocolo WebSocket) a cada PingInterval e
+// encerra a conexão se nada chega do cliente em PingInterval + PongTimeout
+const (
+	DefaultPingInterval = 20 * time.Second
+	DefaultPongTimeout  = 10 * time.Second
+	DefaultIdleTimeout  = 5 * time.Minute
+
+	// limites do intervalo negociável pelo cliente
+	MinHeartbeat = 5 * time.Second
+	MaxHeartbeat = 2 * time.Minute
 )
 
-// Hub gerencia conexões WebSocket e assinaturas de eventos de odds
+// Hub gerencia conexões WebSocket e SSE e assinaturas de eventos de odds
 // subs: mapeia eventID para o conjunto de conexões inscritas
+//
+// Cada conexão tem uma fila de saída limitada drenada por uma goroutine de escrita,
+// de modo que um cliente lento não atrasa o fan-out para os demais
 type Hub struct {
-	upgrader websocket.Upgrader
-	mu       sync.RWMutex
+	upgrader  websocket.Upgrader
+	snapshots *Snapshots
+	stream    *oddsstream.Stream // frames numerados recentes, para replay e preenchimento de lacunas
+	mu        sync.RWMutex
 	// eventID -> set of connections
-	subs map[string]map[*websocket.Conn]struct{}
+	subs    map[string]map[*client]struct{}
+	topics  map[string]map[*client]struct{} // tópico (ex.: "sport:football") -> conexões
+	clients map[*client]struct{}
+
+	// última sequência entregue por evento (Broadcast); descartada quando o último inscrito
+	// do evento sai ou o evento é liquidado
+	seqMu      sync.Mutex
+	lastSeq    map[string]int64
+	recovering map[string]*recovery // eventID -> worker de recuperação ativo
+
+	SendQueueSize      int           // frames por conexão
+	WriteTimeout       time.Duration // prazo de cada escrita na conexão
+	SlowConsumerPolicy string        // PolicyDisconnect | PolicyDrop
+	PingInterval       time.Duration // intervalo padrão de ping (o cliente pode negociar outro)
+	PongTimeout        time.Duration // tolerância além do intervalo para o pong chegar
+	IdleTimeout        time.Duration // tempo máximo de uma conexão sem inscrições
+	MaxSubscriptions   int           // eventos + tópicos por conexão (0 = sem limite)
+
+	OnEvicted    func(reason string) // conexão encerrada pelo Hub (EvictQueueFull | EvictWriteTimeout)
+	OnDropped    func()              // delta descartado (PolicyDrop)
+	OnDisconnect func(reason string) // conexão encerrada, com o motivo (Disconnect* ou Evict*)
 }
 
-// NewHub cria uma instância de Hub com política customizada de origem (CORS)
-func NewHub(allowOrigin func(r *http.Request) bool) *Hub {
+// NewHub cria uma instância de Hub com política customizada de origem (CORS),
+// a fonte dos snapshots enviados na inscrição e o stream usado para replay
+func NewHub(allowOrigin func(r *http.Request) bool, snapshots *Snapshots, stream *oddsstream.Stream) *Hub {
 	return &Hub{
-		upgrader: websocket.Upgrader{CheckOrigin: allowOrigin},
-		subs:     make(map[string]map[*websocket.Conn]struct{}),
+		upgrader:           websocket.Upgrader{CheckOrigin: allowOrigin},
+		snapshots:          snapshots,
+		stream:             stream,
+		subs:               make(map[string]map[*client]struct{}),
+		topics:             make(map[string]map[*client]struct{}),
+		clients:            make(map[*client]struct{}),
+		lastSeq:            make(map[string]int64),
+		recovering:         make(map[string]*recovery),
+		SendQueueSize:      DefaultSendQueueSize,
+		WriteTimeout:       DefaultWriteTimeout,
+		SlowConsumerPolicy: PolicyDisconnect,
+		PingInterval:       DefaultPingInterval,
+		PongTimeout:        DefaultPongTimeout,
+		IdleTimeout:        DefaultIdleTimeout,
+		MaxSubscriptions:   DefaultMaxSubscriptions,
 	}
 }
 
 // HandleWS gerencia o ciclo de vida de uma conexão WebSocket
-// Permite subscribe/unsubscribe em eventos e responde a pings
-// Cada cliente pode se inscrever em múltiplos eventIDs
+// Permite subscribe/unsubscribe em eventos e tópicos, responde a pings e negocia o heartbeat
+// Cada cliente pode se inscrever em múltiplos eventIDs e tópicos, até MaxSubscriptions
 func (h *Hub) HandleWS(w http.ResponseWriter, r *http.Request) {
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
-	defer conn.Close()
+	c := newClient(wsConn{conn}, h.SendQueueSize, h.PingInterval)
+	h.register(c)
+	go h.writeLoop(c)
+
+	// qualquer frame do cliente (inclusive pong) renova o prazo de leitura
+	_ = conn.SetReadDeadline(h.readDeadline(c))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(h.readDeadline(c))
+	})
 
 	for {
-		var msg ClientMsg
-		if err := conn.ReadJSON(&msg); err != nil {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			c.closeWith(readErrorReason(err))
 			break
 		}
+		_ = conn.SetReadDeadline(h.readDeadline(c))
+		var msg ClientMsg
+		if err := json.Unmarshal(data, &msg); err != nil {
+			h.reply(c, ServerMsg{Type: MsgError, Error: ErrCodeInvalidMessage, Message: err.Error()})
+			continue
+		}
 		switch msg.Type {
 		case "subscribe":
-			h.mu.Lock()
-			if _, ok := h.subs[msg.EventID]; !ok {
-				h.subs[msg.EventID] = make(map[*websocket.Conn]struct{})
-			}
-			h.subs[msg.EventID][conn] = struct{}{}
-			h.mu.Unlock()
+			h.handleSubscribe(r.Context(), c, msg)
 		case "unsubscribe":
-			h.mu.Lock()
-			if m, ok := h.subs[msg.EventID]; ok {
-				delete(m, conn)
-				if len(m) == 0 {
-					delete(h.subs, msg.EventID)
-				}
-			}
-			h.mu.Unlock()
+			h.handleUnsubscribe(c, msg)
 		case "ping":
-			_ = conn.WriteJSON(map[string]string{"type": "pong"})
+			h.reply(c, ServerMsg{Type: MsgPong})
+		case "heartbeat":
+			d := h.PingInterval // intervalMs ausente volta ao padrão do servidor
+			if msg.IntervalMs > 0 {
+				d = min(max(time.Duration(msg.IntervalMs)*time.Millisecond, MinHeartbeat), MaxHeartbeat)
+			}
+			c.setHeartbeat(d)
+			_ = conn.SetReadDeadline(h.readDeadline(c))
+			h.reply(c, ServerMsg{Type: MsgHeartbeat, IntervalMs: d.Milliseconds()})
+		default:
+			h.reply(c, ServerMsg{Type: MsgError, Error: ErrCodeUnknownType, Message: msg.Type})
 		}
 	}
-	// Remove a conexão de todas as assinaturas ao desconectar
+	h.unregister(c)
+}
+
+func (h *Hub) register(c *client) {
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+}
+
+// unregister remove a conexão encerrada de todas as assinaturas
+func (h *Hub) unregister(c *client) {
 	h.mu.Lock()
-	for _, set := range h.subs {
-		delete(set, conn)
+	delete(h.clients, c)
+	for id := range c.events {
+		if set, ok := h.subs[id]; ok {
+			delete(set, c)
+			if len(set) == 0 {
+				delete(h.subs, id)
+				h.forgetSeq(id)
+			}
+		}
+	}
+	for t := range c.topics {
+		if set, ok := h.topics[t]; ok {
+			delete(set, c)
+			if len(set) == 0 {
+				delete(h.topics, t)
+			}
+		}
 	}
 	h.mu.Unlock()
+	if h.OnDisconnect != nil {
+		h.OnDisconnect(c.reason)
+	}
+}
+
+// readDeadline é o prazo para o próximo frame do cliente: um intervalo de ping mais a tolerância do pong
+func (h *Hub) readDeadline(c *client) time.Time {
+	return time.Now().Add(time.Duration(c.heartbeat.Load()) + h.PongTimeout)
+}
+
+// readErrorReason classifica o erro de leitura que encerrou a conexão
+func readErrorReason(err error) string {
+	var ce *websocket.CloseError
+	var ne net.Error
+	switch {
+	case errors.As(err, &ce):
+		return DisconnectClientClosed
+	case errors.As(err, &ne) && ne.Timeout():
+		return DisconnectPongTimeout
+	default:
+		return DisconnectReadError
+	}
+}
+
+// writeLoop é a única goroutine que escreve na conexão: drena a fila de envio, envia os pings
+// do heartbeat e encerra conexões sem inscrições além do IdleTimeout ou cuja escrita não
+// termina dentro do WriteTimeout
+func (h *Hub) writeLoop(c *client) {
+	ticker := time.NewTicker(time.Duration(c.heartbeat.Load()))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.reset:
+			ticker.Reset(time.Duration(c.heartbeat.Load()))
+		case <-ticker.C:
+			if since := c.idleSince.Load(); since != 0 && h.IdleTimeout > 0 &&
+				time.Since(time.Unix(0, since)) > h.IdleTimeout {
+				c.closeWith(DisconnectIdle)
+				return
+			}
+			if err := c.conn.ping(time.Now().Add(h.WriteTimeout)); err != nil {
+				h.writeFailed(c, err)
+				return
+			}
+		case b := <-c.send:
+			if err := c.conn.write(b, time.Now().Add(h.WriteTimeout)); err != nil {
+				h.writeFailed(c, err)
+				return
+			}
+		}
+	}
 }
 
-// Broadcast envia uma atualização de odds para todos os clientes inscritos no eventID correspondente
+// writeFailed encerra a conexão após uma falha de escrita
+func (h *Hub) writeFailed(c *client, err error) {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		h.evict(c, EvictWriteTimeout)
+		return
+	}
+	c.closeWith(DisconnectWriteError)
+}
+
+func (h *Hub) add(c *client, eventID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[eventID]; !ok {
+		h.subs[eventID] = make(map[*client]struct{})
+	}
+	h.subs[eventID][c] = struct{}{}
+	c.events[eventID] = struct{}{}
+	c.idleSince.Store(0)
+}
+
+func (h *Hub) remove(c *client, eventID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m, ok := h.subs[eventID]; ok {
+		delete(m, c)
+		if len(m) == 0 {
+			delete(h.subs, eventID)
+			h.forgetSeq(eventID)
+		}
+	}
+	delete(c.events, eventID)
+	c.markIdle()
+}
+
+// reply enfileira um frame de controle para o cliente
+func (h *Hub) reply(c *client, m ServerMsg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h.push(c, marshal(m), false)
+}
+
+// push enfileira o frame aplicando a política de cliente lento; chamado com c.mu travado
+func (h *Hub) push(c *client, b []byte, delta bool) {
+	if b == nil || c.offer(b) {
+		return
+	}
+	h.overflow(c, delta)
+}
+
+// overflow aplica a política de cliente lento a um frame que não coube na fila
+func (h *Hub) overflow(c *client, delta bool) {
+	if delta && h.SlowConsumerPolicy == PolicyDrop {
+		if h.OnDropped != nil {
+			h.OnDropped()
+		}
+		return
+	}
+	h.evict(c, EvictQueueFull)
+}
+
+// evict encerra a conexão de um cliente lento
+func (h *Hub) evict(c *client, reason string) {
+	if c.closeWith(reason) && h.OnEvicted != nil {
+		h.OnEvicted(reason)
+	}
+}
+
+// Broadcast envia uma atualização de odds para todos os clientes inscritos no evento ou em um
+// tópico que o inclui (todos, modalidade ou competição); cada cliente recebe o frame uma vez.
+// Não bloqueia: cada cliente recebe o frame na sua fila de envio. Sequências repetidas são
+// ignoradas; lacunas (mensagens perdidas do Pub/Sub) e sequências menores que a última entregue
+// (possível reinício da sequência do evento) vão para o worker de recuperação do evento, que
+// consulta o stream sem segurar a goroutine do subscriber, e os frames seguintes do evento
+// aguardam na fila do worker para manter a ordem
 func (h *Hub) Broadcast(update OddsUpdate) {
-	h.mu.RLock()
-	conns := h.subs[update.EventID]
-	h.mu.RUnlock()
-	if len(conns) == 0 {
+	rt := route{eventID: update.EventID, sport: update.Sport, competition: update.Competition}
+	update.Type = MsgOdds
+	b := marshal(update)
+	if update.Seq > 0 {
+		h.seqMu.Lock()
+		last := h.lastSeq[update.EventID]
+		stale := update.Seq <= last // repetida, já entregue pelo preenchimento de lacuna ou reinício
+		if !stale {
+			h.trackSeq(update.EventID, update.Seq, update.Status)
+		}
+		job := recoveryJob{rt: rt, seq: update.Seq, frame: b}
+		handled := true // descartado ou entregue pelo worker de recuperação
+		switch {
+		case stale && update.Seq < last:
+			h.enqueueRecovery(recoveryJob{rt: rt, resetFrom: last})
+		case stale:
+		case last > 0 && update.Seq > last+1:
+			job.gapFrom = last
+			h.enqueueRecovery(job)
+		case h.recovering[update.EventID] != nil:
+			h.enqueueRecovery(job)
+		default:
+			handled = false
+		}
+		h.seqMu.Unlock()
+		if handled {
+			return
+		}
+	}
+	h.deliver(rt, update.Seq, b)
+}
+
+// trackSeq registra a última sequência entregue do evento; chamado com seqMu travado.
+// Mercados liquidados encerram o evento e a entrada é descartada
+func (h *Hub) trackSeq(eventID string, seq int64, status string) {
+	if status == events.MarketStatusSettled {
+		delete(h.lastSeq, eventID)
+		return
+	}
+	h.lastSeq[eventID] = seq
+}
+
+// checkSeqReset trata um frame com sequência menor que a última entregue (last): se a sequência
+// atual do stream também está abaixo de last, o contador do evento foi reiniciado (ex.: chaves
+// expiradas no Redis, mesmo que o frame seq 1 tenha se perdido). Os inscritos recebem resync com
+// o snapshot, que cobre o frame recebido, e a entrega continua a partir da sequência atual
+func (h *Hub) checkSeqReset(rt route, last int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	cur, err := h.stream.Seq(ctx, rt.eventID)
+	if err != nil || cur >= last {
 		return
 	}
+	h.seqMu.Lock()
+	if h.lastSeq[rt.eventID] != last {
+		// outro frame já atualizou a sequência do evento
+		h.seqMu.Unlock()
+		return
+	}
+	h.lastSeq[rt.eventID] = cur
+	h.seqMu.Unlock()
+	h.resyncSubscribers(rt, ResyncSeqReset)
+}
 
-	b, _ := json.Marshal(update)
-	for c := range conns {
-		_ = c.WriteMessage(websocket.TextMessage, b)
+// forgetSeq descarta a última sequência do evento quando o último inscrito nele sai; deltas
+// recebidos depois (ex.: por inscrições em tópicos) voltam a registrá-la
+func (h *Hub) forgetSeq(eventID string) {
+	h.seqMu.Lock()
+	delete(h.lastSeq, eventID)
+	h.seqMu.Unlock()
+}
+
+// deliver enfileira um delta para os inscritos do evento e dos seus tópicos; durante a inscrição
+// de um cliente no evento o frame fica pendente e é enviado depois do snapshot/replay
+func (h *Hub) deliver(rt route, seq int64, b []byte) {
+	for _, c := range h.subscribers(rt) {
+		c.mu.Lock()
+		if buf, ok := c.pending[rt.eventID]; ok {
+			if len(buf) < cap(c.send) {
+				c.pending[rt.eventID] = append(buf, pendingFrame{seq: seq, b: b})
+			} else {
+				h.overflow(c, true)
+			}
+		} else {
+			h.push(c, b, true)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// subscribers retorna as conexões inscritas no evento ou em algum dos seus tópicos, sem repetição
+func (h *Hub) subscribers(rt route) []*client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	sets := []map[*client]struct{}{h.subs[rt.eventID]}
+	for _, t := range rt.topics() {
+		if set := h.topics[t]; len(set) > 0 {
+			sets = append(sets, set)
+		}
+	}
+	conns := make([]*client, 0, len(sets[0]))
+	if len(sets) == 1 {
+		for c := range sets[0] {
+			conns = append(conns, c)
+		}
+		return conns
+	}
+	seen := make(map[*client]struct{})
+	for _, set := range sets {
+		for c := range set {
+			if _, ok := seen[c]; !ok {
+				seen[c] = struct{}{}
+				conns = append(conns, c)
+			}
+		}
+	}
+	return conns
+}
+
+// Connections retorna o número de conexões abertas por transporte
+func (h *Hub) Connections() (ws, sse int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		if c.sse {
+			sse++
+		} else {
+			ws++
+		}
+	}
+	return ws, sse
+}
+
+// QueueStats retorna o total de frames aguardando envio e a maior fila entre as conexões
+func (h *Hub) QueueStats() (total, max int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		n := len(c.send)
+		total += n
+		if n > max {
+			max = n
+		}
+	}
+	return total, max
+}
+
+func marshal(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
 	}
+	return b
 }
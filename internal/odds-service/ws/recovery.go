@@ -0,0 +1,88 @@
+package ws
+
+// recoveryQueueSize limita os frames de um evento que aguardam o worker de recuperação; se a
+// fila enche, os excedentes são descartados e os inscritos recebem resync ao final
+const recoveryQueueSize = 256
+
+// recoveryJob é um frame (ou verificação) que depende do stream para ser entregue em ordem
+type recoveryJob struct {
+	rt        route
+	seq       int64
+	frame     []byte
+	gapFrom   int64 // última sequência entregue antes da lacuna a preencher
+	resetFrom int64 // última sequência entregue antes de um frame com sequência menor
+}
+
+// recovery é o worker de um evento com lacuna ou reinício de sequência pendente
+type recovery struct {
+	jobs         chan recoveryJob
+	rt           route
+	overflow     bool // frames descartados com a fila cheia
+	resetPending bool // verificação de reinício já na fila
+}
+
+// enqueueRecovery coloca o frame na fila do worker do evento, criando-o se necessário;
+// chamado com seqMu travado e nunca bloqueia
+func (h *Hub) enqueueRecovery(job recoveryJob) {
+	id := job.rt.eventID
+	r := h.recovering[id]
+	if r == nil {
+		r = &recovery{jobs: make(chan recoveryJob, recoveryQueueSize)}
+		h.recovering[id] = r
+		go h.recover(id, r)
+	}
+	r.rt = job.rt
+	if job.resetFrom > 0 {
+		if r.resetPending {
+			return
+		}
+		r.resetPending = true
+	}
+	select {
+	case r.jobs <- job:
+	default:
+		r.overflow = true
+		if job.resetFrom > 0 {
+			r.resetPending = false
+		}
+	}
+}
+
+// recover processa a fila do evento em ordem e encerra quando ela esvazia. Se frames foram
+// descartados, a lacuna não é mais recuperável pelo worker e os inscritos recebem resync
+func (h *Hub) recover(eventID string, r *recovery) {
+	for {
+		h.seqMu.Lock()
+		select {
+		case job := <-r.jobs:
+			if job.resetFrom > 0 {
+				r.resetPending = false
+			}
+			h.seqMu.Unlock()
+			h.runRecovery(job)
+			continue
+		default:
+		}
+		if !r.overflow {
+			// fila vazia com seqMu travado: o próximo Broadcast do evento volta a entregar direto
+			delete(h.recovering, eventID)
+			h.seqMu.Unlock()
+			return
+		}
+		r.overflow = false
+		rt := r.rt
+		h.seqMu.Unlock()
+		h.resyncSubscribers(rt, ResyncGap)
+	}
+}
+
+func (h *Hub) runRecovery(job recoveryJob) {
+	if job.resetFrom > 0 {
+		h.checkSeqReset(job.rt, job.resetFrom)
+		return
+	}
+	if job.gapFrom > 0 {
+		h.fillGap(job.rt, job.gapFrom, job.seq)
+	}
+	h.deliver(job.rt, job.seq, job.frame)
+}
@@ -6,18 +6,21 @@ import (
 	"log"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddsstream"
 )
 
 // PubSubChannel define o canal Redis Pub/Sub utilizado para broadcast de odds
-const PubSubChannel = "odds_updates_broadcast"
+const PubSubChannel = oddsstream.Channel
 
 // StartRedisSubscriber inicia uma goroutine que escuta o canal Redis Pub/Sub
 // e repassa as atualizações recebidas para todos os clientes WebSocket conectados via Hub
 //
 // Funcionamento:
-// - Recebe mensagens JSON do canal Redis
-// - Desserializa para OddsUpdate
-// - Chama hub.Broadcast para enviar aos clientes conectados
+//   - Recebe mensagens JSON do canal Redis
+//   - Desserializa para OddsUpdate (frame numerado, também gravado no stream do evento)
+//   - Chama hub.Broadcast para enviar aos clientes conectados; mensagens perdidas
+//     são recuperadas do stream pela sequência num worker por evento, sem bloquear a leitura
 func StartRedisSubscriber(ctx context.Context, r *redis.Client, hub *Hub) {
 	sub := r.Subscribe(ctx, PubSubChannel)
 	ch := sub.Channel()
@@ -36,6 +39,13 @@ func StartRedisSubscriber(ctx context.Context, r *redis.Client, hub *Hub) {
 					log.Printf("ws subscriber unmarshal error: %v", err)
 					continue
 				}
+				var rt struct {
+					Sport       string `json:"sport"`
+					Competition string `json:"competition"`
+					Status      string `json:"status"`
+				}
+				_ = json.Unmarshal(upd.Payload, &rt)
+				upd.Sport, upd.Competition, upd.Status = rt.Sport, rt.Competition, rt.Status
 				hub.Broadcast(upd) // envia atualização para todos os clientes inscritos
 			}
 		}
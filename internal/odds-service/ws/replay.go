@@ -0,0 +1,166 @@
+package ws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddsstream"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// pendingFrame é um delta recebido enquanto a inscrição do cliente no evento é montada
+type pendingFrame struct {
+	seq int64
+	b   []byte
+}
+
+// subscribe inscreve o cliente no evento.
+//
+// Com fromSeq (última sequência recebida pelo cliente) ainda coberta pelo stream, envia
+// subscribed seguido dos deltas perdidos. Sem fromSeq, se o stream já não cobre a lacuna ou se
+// os deltas não cabem no espaço livre da fila de envio, envia resync (apenas quando fromSeq foi
+// pedido), subscribed e o snapshot atual com a sequência que ele cobre.
+//
+// A inscrição é registrada antes das leituras e os deltas do evento que chegam nesse meio-tempo
+// são enfileirados depois do snapshot/replay, descartando as sequências já cobertas por eles.
+// Retorna false se o evento é desconhecido (a inscrição é desfeita). ready, se informado, é
+// chamado quando o evento é aceito e antes de qualquer frame entrar na fila, para que o SSE
+// comece a escrever a resposta e a drenar a fila durante o replay
+func (h *Hub) subscribe(ctx context.Context, c *client, eventID string, fromSeq int64, ready func()) bool {
+	c.mu.Lock()
+	c.pending[eventID] = nil
+	c.mu.Unlock()
+	h.add(c, eventID)
+
+	sctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	var (
+		frames  []oddsstream.Entry
+		resync  string
+		markets []events.OddsUpdate
+	)
+	// a sequência é lida antes do snapshot: o odds-processor grava o cache antes de numerar
+	// o frame, então o snapshot cobre ao menos até seq
+	seq, err := h.stream.Seq(sctx, eventID)
+	if err == nil && fromSeq > 0 {
+		frames, resync, err = h.replay(sctx, eventID, fromSeq, seq)
+	}
+	// o replay entra na fila de uma vez: se não couber (além do subscribed), o snapshot substitui
+	// os deltas em vez de derrubar (PolicyDisconnect) ou truncar (PolicyDrop) a retomada
+	if err == nil && resync == "" && len(frames) > c.free()-1 {
+		frames, resync = nil, ResyncTooLarge
+	}
+	resumed := err == nil && fromSeq > 0 && resync == ""
+	if err == nil && !resumed {
+		markets, err = h.snapshots.Event(sctx, eventID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	buffered := c.pending[eventID]
+	delete(c.pending, eventID)
+
+	if errors.Is(err, ErrUnknownEvent) {
+		h.remove(c, eventID)
+		h.push(c, marshal(ServerMsg{Type: MsgError, EventID: eventID, Error: ErrCodeUnknownEvent}), false)
+		return false
+	}
+	if ready != nil {
+		ready()
+	}
+	if resync != "" {
+		h.push(c, marshal(ServerMsg{Type: MsgResync, EventID: eventID, Reason: resync}), false)
+	}
+	h.push(c, marshal(ServerMsg{Type: MsgSubscribed, EventID: eventID}), false)
+
+	var covered int64
+	switch {
+	case err != nil:
+		// mantém a inscrição: o cliente recebe os próximos deltas
+		h.push(c, marshal(ServerMsg{Type: MsgError, EventID: eventID, Error: ErrCodeSnapshotUnavailable, Message: err.Error()}), false)
+	case resumed:
+		covered = fromSeq
+		for _, f := range frames {
+			h.push(c, f.Frame, true)
+			covered = f.Seq
+		}
+	default:
+		covered = seq
+		h.push(c, marshal(ServerMsg{Type: MsgSnapshot, EventID: eventID, Seq: seq, Payload: markets}), false)
+	}
+	for _, p := range buffered {
+		if p.seq == 0 || p.seq > covered {
+			h.push(c, p.b, true)
+		}
+	}
+	return true
+}
+
+// replay retorna os frames após fromSeq, ou o motivo do resync se o stream não cobre a lacuna
+func (h *Hub) replay(ctx context.Context, eventID string, fromSeq, seq int64) ([]oddsstream.Entry, string, error) {
+	if fromSeq > seq {
+		return nil, ResyncSeqReset, nil
+	}
+	if fromSeq == seq {
+		return nil, "", nil
+	}
+	frames, err := h.stream.After(ctx, eventID, fromSeq)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(frames) == 0 || frames[0].Seq != fromSeq+1 {
+		return nil, ResyncTooOld, nil
+	}
+	return frames, "", nil
+}
+
+// fillGap entrega os frames entre last e next (exclusivos) perdidos no Pub/Sub; se o stream
+// já os descartou, força o resync dos inscritos
+func (h *Hub) fillGap(rt route, last, next int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	frames, err := h.stream.After(ctx, rt.eventID, last)
+	if err != nil || len(frames) == 0 || frames[0].Seq != last+1 {
+		h.resyncSubscribers(rt, ResyncGap)
+		return
+	}
+	for _, f := range frames {
+		if f.Seq >= next {
+			break
+		}
+		h.deliver(rt, f.Seq, f.Frame)
+	}
+}
+
+// resyncSubscribers envia resync e um novo snapshot do evento a todos os inscritos do evento
+// e dos seus tópicos (os que estão se inscrevendo no evento recebem o próprio snapshot)
+func (h *Hub) resyncSubscribers(rt route, reason string) {
+	eventID := rt.eventID
+	conns := h.subscribers(rt)
+	if len(conns) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	snap := ServerMsg{Type: MsgSnapshot, EventID: eventID}
+	seq, err := h.stream.Seq(ctx, eventID)
+	if err == nil {
+		snap.Seq = seq
+		snap.Payload, err = h.snapshots.Event(ctx, eventID)
+	}
+	if err != nil {
+		snap = ServerMsg{Type: MsgError, EventID: eventID, Error: ErrCodeSnapshotUnavailable, Message: err.Error()}
+	}
+	msgs := [][]byte{marshal(ServerMsg{Type: MsgResync, EventID: eventID, Reason: reason}), marshal(snap)}
+
+	for _, c := range conns {
+		c.mu.Lock()
+		if _, ok := c.pending[eventID]; !ok {
+			for _, b := range msgs {
+				h.push(c, b, false)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
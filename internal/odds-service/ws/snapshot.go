@@ -0,0 +1,62 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/internal/odds-service/repo"
+	"github.com/radieske/sports-bet-platform-poc/internal/shared/oddscache"
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// ErrUnknownEvent indica que não há odds do evento nem no cache nem no banco
+var ErrUnknownEvent = errors.New("unknown event")
+
+// Snapshots carrega o estado atual dos mercados de um evento, enviado ao cliente na inscrição
+type Snapshots struct {
+	Cache    *oddscache.Cache // cache compartilhado (gravado pelo odds-processor)
+	ReadRepo *repo.ReadRepo   // fallback quando o evento não está em cache
+}
+
+// Event retorna os mercados do evento no mesmo formato dos deltas (payload de OddsUpdate),
+// preferencialmente do cache; ErrUnknownEvent se o evento não tem odds
+func (s *Snapshots) Event(ctx context.Context, eventID string) ([]events.OddsUpdate, error) {
+	snaps, err := s.Cache.Event(ctx, eventID)
+	if err == nil {
+		out := make([]events.OddsUpdate, 0, len(snaps))
+		for _, m := range snaps {
+			out = append(out, m.OddsUpdate())
+		}
+		return out, nil
+	}
+	if !errors.Is(err, oddscache.ErrNotFound) {
+		return nil, err
+	}
+
+	od, err := s.ReadRepo.GetOddsByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if len(od) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	out := make([]events.OddsUpdate, 0, len(od))
+	for _, o := range od {
+		sel := make([]events.Selection, 0, len(o.Selections))
+		for _, x := range o.Selections {
+			sel = append(sel, events.Selection{Name: x.Name, Odd: x.Odd})
+		}
+		at, _ := time.Parse(time.RFC3339, o.UpdatedAt)
+		out = append(out, events.OddsUpdate{
+			EventID:    o.EventID,
+			Market:     o.Market,
+			Line:       o.Line,
+			Selections: sel,
+			Status:     events.NormalizeMarketStatus(o.Status),
+			UpdatedAt:  at,
+			Version:    o.Version,
+		})
+	}
+	return out, nil
+}
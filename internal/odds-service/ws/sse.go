@@ -0,0 +1,113 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseConn entrega os frames como eventos Server-Sent Events: o campo data leva o mesmo JSON
+// enviado pelo WebSocket e o id é a sequência do evento (frames odds e snapshot), que o
+// navegador devolve em Last-Event-ID ao reconectar
+type sseConn struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s sseConn) write(b []byte, deadline time.Time) error {
+	_ = s.rc.SetWriteDeadline(deadline)
+	var meta struct {
+		Seq int64 `json:"seq"`
+	}
+	_ = json.Unmarshal(b, &meta)
+	var buf []byte
+	if meta.Seq > 0 {
+		buf = append(buf, "id: "...)
+		buf = strconv.AppendInt(buf, meta.Seq, 10)
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, "data: "...)
+	buf = append(buf, b...)
+	buf = append(buf, "\n\n"...)
+	if _, err := s.w.Write(buf); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// ping envia uma linha de comentário, ignorada pelo EventSource, que mantém proxies
+// e balanceadores com a conexão aberta
+func (s sseConn) ping(deadline time.Time) error {
+	_ = s.rc.SetWriteDeadline(deadline)
+	if _, err := s.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// close não tem efeito: a resposta termina quando HandleSSE retorna
+func (s sseConn) close() error { return nil }
+
+// HandleSSE transmite as atualizações de um evento via Server-Sent Events
+// (GET /v1/stream/odds?eventId=...), para clientes que não mantêm WebSockets abertos.
+//
+// Usa o mesmo fan-out do Hub (alimentado pelo Redis Pub/Sub) e os mesmos frames do protocolo
+// WebSocket: subscribed, snapshot e deltas odds. Com Last-Event-ID, retoma a partir da
+// sequência como o fromSeq do WebSocket; heartbeats são comentários a cada PingInterval
+func (h *Hub) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	eventID := r.URL.Query().Get("eventId")
+	if eventID == "" {
+		sseError(w, http.StatusBadRequest, ErrCodeEventRequired)
+		return
+	}
+	fromSeq, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	if err != nil || fromSeq < 0 {
+		fromSeq = 0
+	}
+
+	rc := http.NewResponseController(w)
+	c := newClient(sseConn{w: w, rc: rc}, h.SendQueueSize, h.PingInterval)
+	c.sse = true
+
+	// o writer começa antes do snapshot/replay entrar na fila: sem ele, uma retomada maior que a
+	// fila derrubaria a conexão antes do primeiro byte e o EventSource reconectaria em loop
+	written := make(chan struct{})
+	start := func() {
+		h.register(c)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no") // desliga o buffer de proxies como o nginx
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			c.closeWith(DisconnectWriteError)
+		}
+		go func() {
+			h.writeLoop(c)
+			close(written)
+		}()
+	}
+	if !h.subscribe(r.Context(), c, eventID, fromSeq, start) {
+		sseError(w, http.StatusNotFound, ErrCodeUnknownEvent)
+		return
+	}
+
+	go func() {
+		select {
+		case <-r.Context().Done():
+			c.closeWith(DisconnectClientClosed)
+		case <-c.done:
+		}
+	}()
+	// a resposta não pode ser usada após o retorno do handler: espera o writer terminar
+	<-written
+	h.unregister(c)
+}
+
+// sseError responde antes do stream começar; o EventSource não reconecta em status diferente de 200
+func sseError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
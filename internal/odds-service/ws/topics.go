@@ -0,0 +1,177 @@
+package ws
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Tópicos de inscrição além de eventos individuais. Atualizações são casadas por
+// consulta direta aos índices (evento, "all", "sport:{sport}", "competition:{competition}"),
+// de modo que o custo do Broadcast não cresce com o número de tópicos
+const (
+	TopicAll               = "all"
+	topicPrefixEvent       = "event:"
+	topicPrefixSport       = "sport:"
+	topicPrefixCompetition = "competition:"
+)
+
+// DefaultMaxSubscriptions limita eventos + tópicos inscritos por conexão
+const DefaultMaxSubscriptions = 500
+
+// route identifica os inscritos de uma atualização: o evento e os tópicos que ele integra
+type route struct {
+	eventID     string
+	sport       string
+	competition string
+}
+
+// topics retorna as chaves de tópico que a atualização casa
+func (r route) topics() []string {
+	out := []string{TopicAll}
+	if r.sport != "" {
+		out = append(out, topicPrefixSport+strings.ToLower(r.sport))
+	}
+	if r.competition != "" {
+		out = append(out, topicPrefixCompetition+strings.ToLower(r.competition))
+	}
+	return out
+}
+
+// parseTopic normaliza o tópico; "event:{id}" retorna o evento em eventID
+func parseTopic(s string) (topic, eventID string, ok bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, topicPrefixEvent) {
+		id := strings.TrimPrefix(s, topicPrefixEvent)
+		return "", id, id != ""
+	}
+	s = strings.ToLower(s)
+	if s == TopicAll {
+		return s, "", true
+	}
+	for _, p := range []string{topicPrefixSport, topicPrefixCompetition} {
+		if strings.HasPrefix(s, p) && len(s) > len(p) {
+			return s, "", true
+		}
+	}
+	return "", "", false
+}
+
+// subscriptionTargets extrai os eventos e tópicos de subscribe/unsubscribe
+// (eventId, eventIds e topic podem ser combinados)
+func subscriptionTargets(msg ClientMsg) (ids []string, topic string, errCode string) {
+	if msg.EventID != "" {
+		ids = append(ids, msg.EventID)
+	}
+	for _, id := range msg.EventIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if msg.Topic != "" {
+		t, id, ok := parseTopic(msg.Topic)
+		if !ok {
+			return nil, "", ErrCodeInvalidTopic
+		}
+		if id != "" {
+			ids = append(ids, id)
+		}
+		topic = t
+	}
+	if len(ids) == 0 && topic == "" {
+		return nil, "", ErrCodeEventRequired
+	}
+	return dedupe(ids), topic, ""
+}
+
+// handleSubscribe inscreve o cliente nos eventos (cada um com confirmação e snapshot/replay)
+// e no tópico (confirmação; apenas deltas), respeitando o limite de inscrições da conexão
+func (h *Hub) handleSubscribe(ctx context.Context, c *client, msg ClientMsg) {
+	ids, topic, code := subscriptionTargets(msg)
+	if code != "" {
+		h.reply(c, ServerMsg{Type: MsgError, Error: code, Message: msg.Topic})
+		return
+	}
+
+	h.mu.RLock()
+	n := len(c.events) + len(c.topics)
+	for _, id := range ids {
+		if _, ok := c.events[id]; !ok {
+			n++
+		}
+	}
+	if _, ok := c.topics[topic]; topic != "" && !ok {
+		n++
+	}
+	h.mu.RUnlock()
+	if h.MaxSubscriptions > 0 && n > h.MaxSubscriptions {
+		h.reply(c, ServerMsg{Type: MsgError, Error: ErrCodeSubscriptionLimit,
+			Message: "max " + strconv.Itoa(h.MaxSubscriptions) + " subscriptions per connection"})
+		return
+	}
+
+	if topic != "" {
+		h.addTopic(c, topic)
+		h.reply(c, ServerMsg{Type: MsgSubscribed, Topic: topic})
+	}
+	fromSeq := int64(0)
+	if len(ids) == 1 {
+		fromSeq = msg.FromSeq // retomada só para inscrição de um único evento
+	}
+	for _, id := range ids {
+		h.subscribe(ctx, c, id, fromSeq, nil)
+	}
+}
+
+// handleUnsubscribe remove as inscrições indicadas
+func (h *Hub) handleUnsubscribe(c *client, msg ClientMsg) {
+	ids, topic, code := subscriptionTargets(msg)
+	if code != "" {
+		h.reply(c, ServerMsg{Type: MsgError, Error: code, Message: msg.Topic})
+		return
+	}
+	if topic != "" {
+		h.removeTopic(c, topic)
+		h.reply(c, ServerMsg{Type: MsgUnsubscribed, Topic: topic})
+	}
+	for _, id := range ids {
+		h.remove(c, id)
+		h.reply(c, ServerMsg{Type: MsgUnsubscribed, EventID: id})
+	}
+}
+
+func (h *Hub) addTopic(c *client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.topics[topic]; !ok {
+		h.topics[topic] = make(map[*client]struct{})
+	}
+	h.topics[topic][c] = struct{}{}
+	c.topics[topic] = struct{}{}
+	c.idleSince.Store(0)
+}
+
+func (h *Hub) removeTopic(c *client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m, ok := h.topics[topic]; ok {
+		delete(m, c)
+		if len(m) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	delete(c.topics, topic)
+	c.markIdle()
+}
+
+func dedupe(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := ids[:0]
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package betstate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Estados de uma aposta (bets.status)
+const (
+	PendingConfirmation = "PENDING_CONFIRMATION"
+	Confirmed           = "CONFIRMED"
+	Rejected            = "REJECTED"
+	Won                 = "WON"
+	Lost                = "LOST"
+	Void                = "VOID"
+	CashedOut           = "CASHED_OUT"
+	Cancelled           = "CANCELLED"
+	// Failed marca apostas cuja colocação foi compensada (reserva de saldo falhou)
+	Failed = "FAILED"
+)
+
+// ErrIllegalTransition indica uma transição não prevista na máquina de estados
+var ErrIllegalTransition = errors.New("illegal bet status transition")
+
+// transitions lista, para cada estado, os estados alcançáveis; estados ausentes são finais
+var transitions = map[string][]string{
+	PendingConfirmation: {Confirmed, Rejected, Cancelled, Failed},
+	Confirmed:           {Won, Lost, Void, CashedOut, Cancelled},
+}
+
+// Valid indica se o status é um estado conhecido
+func Valid(status string) bool {
+	switch status {
+	case PendingConfirmation, Confirmed, Rejected, Won, Lost, Void, CashedOut, Cancelled, Failed:
+		return true
+	}
+	return false
+}
+
+// IsFinal indica se o status não admite novas transições
+func IsFinal(status string) bool {
+	return Valid(status) && len(transitions[status]) == 0
+}
+
+// CanTransition indica se from -> to é uma transição permitida
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Current retorna o status atual da aposta
+func Current(ctx context.Context, db *sql.DB, betID string) (string, error) {
+	var s string
+	err := db.QueryRowContext(ctx, `SELECT status FROM bets WHERE id=$1`, betID).Scan(&s)
+	return s, err
+}
+
+// Transition aplica from -> to em transação própria (ver TransitionTx)
+func Transition(ctx context.Context, db *sql.DB, betID, from, to, reason string) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ok, err := TransitionTx(ctx, tx, betID, from, to, reason)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// TransitionTx move a aposta de from para to com compare-and-set (só altera se o status
+// atual ainda é from) e registra o par old/new real em bet_transactions na mesma transação.
+// Retorna false sem erro quando a aposta já não está em from (ex.: entrega duplicada);
+// transições fora da máquina de estados retornam ErrIllegalTransition.
+func TransitionTx(ctx context.Context, tx *sql.Tx, betID, from, to, reason string) (bool, error) {
+	if !CanTransition(from, to) {
+		return false, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE bets SET status=$3, updated_at=NOW()
+		WHERE id=$1 AND status=$2`, betID, from, to)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bet_transactions (bet_id, old_status, new_status, reason, created_at)
+		VALUES ($1,$2,$3,$4,NOW())`, betID, from, to, reason); err != nil {
+		return false, err
+	}
+	return true, nil
+}
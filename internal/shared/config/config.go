@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	ctopics "github.com/radieske/sports-bet-platform-poc/pkg/contracts/topics"
 )
@@ -22,6 +24,7 @@ type Config struct {
 	TopicBetConfirmed    string
 	TopicBetPlacedDLQ    string
 	TopicBetConfirmedDLQ string
+	TopicMatchResults    string
 	RedisPubSubChannel   string
 
 	// URLs base de dependências HTTP
@@ -33,6 +36,41 @@ type Config struct {
 	// Supplier mock via WebSocket
 	SupplierWSURL string // SUPPLIER_WS_URL (ex.: ws://supplier-simulator:8081/ws)
 
+	// Reconciliação de reservas não efetivadas (bet-confirmation-worker)
+	UncommittedCheckInterval time.Duration // UNCOMMITTED_CHECK_INTERVAL (ex.: 1m)
+	UncommittedGrace         time.Duration // UNCOMMITTED_GRACE (ex.: 2m)
+
+	// Reconciliação de apostas presas em PENDING_CONFIRMATION (bet-confirmation-worker)
+	StuckCheckInterval  time.Duration // STUCK_BET_CHECK_INTERVAL (ex.: 30s)
+	StuckBetAge         time.Duration // STUCK_BET_AGE (ex.: 2m) idade mínima para reprocessar
+	StuckBetRejectAfter time.Duration // STUCK_BET_REJECT_AFTER (ex.: 10m) rejeita e estorna após esta idade
+
+	// Republicação de bet_confirmed não publicado após a decisão (bet-confirmation-worker)
+	UnpublishedCheckInterval time.Duration // UNPUBLISHED_CHECK_INTERVAL (ex.: 30s)
+	UnpublishedGrace         time.Duration // UNPUBLISHED_GRACE (ex.: 1m)
+
+	// Varredura de resultados com apostas ainda não liquidadas (bet-settlement-worker)
+	SettlementSweepInterval time.Duration // SETTLEMENT_SWEEP_INTERVAL (ex.: 1m)
+	SettlementSweepGrace    time.Duration // SETTLEMENT_SWEEP_GRACE (ex.: 2m)
+
+	// Margem da casa aplicada às odds do fornecedor (odds-processor-worker)
+	OddsMargin       string // ODDS_MARGIN (ex.: 0.05 = 5%), validado por pricing.ParseMargin
+	OddsMarginMethod string // ODDS_MARGIN_METHOD: proportional | margin_weights
+	OddsMarginRules  string // ODDS_MARGIN_RULES: "sport/competition/market=margin[:method];..."
+
+	// Stream de odds numeradas para replay nos WebSockets (odds-processor-worker)
+	OddsStreamMaxLen int64         // ODDS_STREAM_MAXLEN (ex.: 1000 frames por evento)
+	OddsStreamTTL    time.Duration // ODDS_STREAM_TTL (ex.: 24h sem atualizações)
+
+	// Fila de envio e heartbeat das conexões WebSocket (odds-service)
+	WSSendQueueSize      int           // WS_SEND_QUEUE_SIZE (ex.: 256 frames)
+	WSWriteTimeout       time.Duration // WS_WRITE_TIMEOUT (ex.: 5s)
+	WSSlowConsumerPolicy string        // WS_SLOW_CONSUMER_POLICY: disconnect | drop
+	WSPingInterval       time.Duration // WS_PING_INTERVAL (ex.: 20s) intervalo padrão de ping
+	WSPongTimeout        time.Duration // WS_PONG_TIMEOUT (ex.: 10s) tolerância para o pong
+	WSIdleTimeout        time.Duration // WS_IDLE_TIMEOUT (ex.: 5m) conexão sem inscrições
+	WSMaxSubscriptions   int           // WS_MAX_SUBSCRIPTIONS (ex.: 500) eventos + tópicos por conexão
+
 	// Portas do serviço atual
 	HTTPPort    string // Porta pública (ex.: API REST)
 	MetricsPort string // Porta exclusiva para /metrics e /healthz
@@ -58,6 +96,7 @@ func Load() Config {
 		TopicBetConfirmed:    getEnv("KAFKA_TOPIC_BET_CONFIRMED", ctopics.BetConfirmed),
 		TopicBetPlacedDLQ:    getEnv("KAFKA_TOPIC_BET_PLACED_DLQ", ctopics.BetPlacedDLQ),
 		TopicBetConfirmedDLQ: getEnv("KAFKA_TOPIC_BET_CONFIRMED_DLQ", ctopics.BetConfirmedDLQ),
+		TopicMatchResults:    getEnv("KAFKA_TOPIC_MATCH_RESULTS", ctopics.MatchResults),
 
 		RedisPubSubChannel: getEnv("REDIS_PUBSUB_CHANNEL", "odds_updates_broadcast"),
 
@@ -67,6 +106,34 @@ func Load() Config {
 		BetBaseURL:      getEnv("BET_URL", "http://bet-service:8083"),
 		SupplierBaseURL: getEnv("SUPPLIER_URL", "http://supplier-simulator:8081"),
 		SupplierWSURL:   getEnv("SUPPLIER_WS_URL", "ws://supplier-simulator:8081/ws"),
+
+		UncommittedCheckInterval: getDuration("UNCOMMITTED_CHECK_INTERVAL", time.Minute),
+		UncommittedGrace:         getDuration("UNCOMMITTED_GRACE", 2*time.Minute),
+
+		StuckCheckInterval:  getDuration("STUCK_BET_CHECK_INTERVAL", 30*time.Second),
+		StuckBetAge:         getDuration("STUCK_BET_AGE", 2*time.Minute),
+		StuckBetRejectAfter: getDuration("STUCK_BET_REJECT_AFTER", 10*time.Minute),
+
+		UnpublishedCheckInterval: getDuration("UNPUBLISHED_CHECK_INTERVAL", 30*time.Second),
+		UnpublishedGrace:         getDuration("UNPUBLISHED_GRACE", time.Minute),
+
+		SettlementSweepInterval: getDuration("SETTLEMENT_SWEEP_INTERVAL", time.Minute),
+		SettlementSweepGrace:    getDuration("SETTLEMENT_SWEEP_GRACE", 2*time.Minute),
+
+		OddsMargin:       getEnv("ODDS_MARGIN", "0.05"),
+		OddsMarginMethod: getEnv("ODDS_MARGIN_METHOD", "proportional"),
+		OddsMarginRules:  getEnv("ODDS_MARGIN_RULES", ""),
+
+		OddsStreamMaxLen: int64(getInt("ODDS_STREAM_MAXLEN", 1000)),
+		OddsStreamTTL:    getDuration("ODDS_STREAM_TTL", 24*time.Hour),
+
+		WSSendQueueSize:      getInt("WS_SEND_QUEUE_SIZE", 256),
+		WSWriteTimeout:       getDuration("WS_WRITE_TIMEOUT", 5*time.Second),
+		WSSlowConsumerPolicy: getEnv("WS_SLOW_CONSUMER_POLICY", "disconnect"),
+		WSPingInterval:       getDuration("WS_PING_INTERVAL", 20*time.Second),
+		WSPongTimeout:        getDuration("WS_PONG_TIMEOUT", 10*time.Second),
+		WSIdleTimeout:        getDuration("WS_IDLE_TIMEOUT", 5*time.Minute),
+		WSMaxSubscriptions:   getInt("WS_MAX_SUBSCRIPTIONS", 500),
 	}
 
 	// Define portas padrão para cada serviço
@@ -89,6 +156,9 @@ func Load() Config {
 	case "supplier-simulator":
 		cfg.HTTPPort = getEnv("HTTP_PORT_SUPPLIER", "8081")
 		cfg.MetricsPort = getEnv("METRICS_PORT_SUPPLIER", "9094")
+	case "bet-settlement-worker":
+		cfg.HTTPPort = getEnv("HTTP_PORT_SETTLEMENT", "")
+		cfg.MetricsPort = getEnv("METRICS_PORT_SETTLEMENT", "9101")
 	case "api-gateway":
 		cfg.HTTPPort = getEnv("HTTP_PORT_GATEWAY", "8000")
 		cfg.MetricsPort = getEnv("METRICS_PORT_GATEWAY", "9100")
@@ -107,3 +177,23 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+// getDuration lê uma duração (ex.: "30s", "5m") ou retorna o default se ausente/inválida
+func getDuration(key string, def time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+// getInt lê um inteiro positivo (ex.: "256") ou retorna o default se ausente/inválido
+func getInt(key string, def int) int {
+	if v, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
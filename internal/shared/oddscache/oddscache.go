@@ -0,0 +1,185 @@
+package oddscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Esquema de chaves compartilhado por odds-processor (escrita), odds-service e bet-service (leitura):
+//
+//	odds:snapshot:{eventId}  HASH  campo = chave do mercado (ex.: "1x2", "over_under:2.5"), valor = MarketSnapshot (JSON)
+//
+// O hash inteiro expira após o TTL sem atualizações, de modo que preços de eventos
+// sem feed não ficam disponíveis para apostas.
+func key(eventID string) string { return "odds:snapshot:" + eventID }
+
+var (
+	// ErrNotFound indica que não há preço em cache para o evento/mercado
+	ErrNotFound = errors.New("odds not found in cache")
+	// ErrSelectionNotFound indica que o mercado existe mas não oferece a seleção
+	ErrSelectionNotFound = errors.New("selection not offered in market")
+	// ErrMarketNotOpen indica que o mercado está suspenso, fechado ou liquidado
+	ErrMarketNotOpen = errors.New("market not open")
+)
+
+// MarketSnapshot é o preço atual de um mercado de um evento
+type MarketSnapshot struct {
+	EventID    string             `json:"event_id"`
+	HomeTeam   string             `json:"home_team,omitempty"`
+	AwayTeam   string             `json:"away_team,omitempty"`
+	Market     string             `json:"market"`
+	Line       string             `json:"line,omitempty"`
+	Selections []events.Selection `json:"selections"` // nomes normalizados, na ordem do fornecedor
+	Status     string             `json:"status"`     // OPEN | SUSPENDED | CLOSED | SETTLED
+	Version    int64              `json:"version"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// Key retorna a chave do mercado no evento (ex.: "over_under:2.5")
+func (m MarketSnapshot) Key() string {
+	return events.NormalizeMarket(events.MarketKey(m.Market, m.Line))
+}
+
+// IsOpen indica se o mercado aceita apostas
+func (m MarketSnapshot) IsOpen() bool {
+	return events.NormalizeMarketStatus(m.Status) == events.MarketStatusOpen
+}
+
+// Price retorna a odd de uma seleção (aceita "1"|"home", "x"|"draw", "2"|"away")
+func (m MarketSnapshot) Price(selection string) (float64, bool) {
+	name := events.NormalizeSelection(selection)
+	for _, s := range m.Selections {
+		if s.Name == name {
+			return s.Odd, s.Odd > 0
+		}
+	}
+	return 0, false
+}
+
+// FromOddsUpdate converte a atualização do fornecedor no snapshot de mercado
+func FromOddsUpdate(u events.OddsUpdate) MarketSnapshot {
+	market, line := events.SplitMarketKey(events.NormalizeMarket(u.MarketKey()))
+	sel := make([]events.Selection, 0, len(u.Selections))
+	for _, x := range u.Selections {
+		sel = append(sel, events.Selection{Name: events.NormalizeSelection(x.Name), Odd: x.Odd})
+	}
+	return MarketSnapshot{
+		EventID:    u.EventID,
+		HomeTeam:   u.HomeTeam,
+		AwayTeam:   u.AwayTeam,
+		Market:     market,
+		Line:       line,
+		Selections: sel,
+		Status:     u.MarketStatus(),
+		Version:    u.Version,
+		UpdatedAt:  u.UpdatedAt,
+	}
+}
+
+// OddsUpdate converte o snapshot de volta no formato publicado aos clientes em tempo real
+func (m MarketSnapshot) OddsUpdate() events.OddsUpdate {
+	return events.OddsUpdate{
+		EventID:    m.EventID,
+		HomeTeam:   m.HomeTeam,
+		AwayTeam:   m.AwayTeam,
+		Market:     m.Market,
+		Line:       m.Line,
+		Selections: m.Selections,
+		Status:     events.NormalizeMarketStatus(m.Status),
+		UpdatedAt:  m.UpdatedAt,
+		Version:    m.Version,
+	}
+}
+
+// Cache lê e grava snapshots de odds no Redis
+type Cache struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+// New cria o cache; ttl é usado apenas na escrita
+func New(rdb *redis.Client, ttl time.Duration) *Cache {
+	return &Cache{rdb: rdb, ttl: ttl}
+}
+
+// SetMarket grava o snapshot do mercado e renova o TTL do evento
+func (c *Cache) SetMarket(ctx context.Context, s MarketSnapshot) error {
+	k := s.Key()
+	s.Market, s.Line = events.SplitMarketKey(k)
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, key(s.EventID), k, b)
+	pipe.Expire(ctx, key(s.EventID), c.ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Market retorna o snapshot de um mercado do evento pela chave (ErrNotFound se ausente)
+func (c *Cache) Market(ctx context.Context, eventID, market string) (MarketSnapshot, error) {
+	var s MarketSnapshot
+	b, err := c.rdb.HGet(ctx, key(eventID), events.NormalizeMarket(market)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return s, ErrNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	return s, json.Unmarshal(b, &s)
+}
+
+// Event retorna os snapshots de todos os mercados do evento, ordenados pela chave do mercado
+// (ErrNotFound se o evento não está em cache)
+func (c *Cache) Event(ctx context.Context, eventID string) ([]MarketSnapshot, error) {
+	all, err := c.rdb.HGetAll(ctx, key(eventID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, ErrNotFound
+	}
+	out := make([]MarketSnapshot, 0, len(all))
+	for _, v := range all {
+		var s MarketSnapshot
+		if err := json.Unmarshal([]byte(v), &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out, nil
+}
+
+// SelectionPrice retorna a odd atual de uma seleção de um mercado aberto
+func (c *Cache) SelectionPrice(ctx context.Context, eventID, market, selection string) (float64, error) {
+	s, err := c.Market(ctx, eventID, market)
+	if err != nil {
+		return 0, err
+	}
+	if !s.IsOpen() {
+		return 0, ErrMarketNotOpen
+	}
+	p, ok := s.Price(selection)
+	if !ok {
+		return 0, ErrSelectionNotFound
+	}
+	return p, nil
+}
+
+// Version retorna a versão do snapshot atual do mercado
+func (c *Cache) Version(ctx context.Context, eventID, market string) (int64, error) {
+	s, err := c.Market(ctx, eventID, market)
+	if err != nil {
+		return 0, err
+	}
+	return s.Version, nil
+}
@@ -0,0 +1,96 @@
+package oddsstream
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Esquema de chaves compartilhado por odds-processor (escrita) e odds-service (leitura):
+//
+//	odds:seq:{eventId}     STRING  último número de sequência publicado do evento
+//	odds:stream:{eventId}  STREAM  frames recentes do evento; ID = "{seq}-0", campo "frame"
+//
+// Cada frame é também publicado no canal Pub/Sub Channel para entrega ao vivo; o stream
+// limitado (MAXLEN) permite reenviar o que um cliente ou uma instância perdeu.
+func seqKey(eventID string) string    { return "odds:seq:" + eventID }
+func streamKey(eventID string) string { return "odds:stream:" + eventID }
+
+// Channel é o canal Redis Pub/Sub das atualizações de odds para os clientes em tempo real
+const Channel = "odds_updates_broadcast"
+
+// Entry é um frame do stream de um evento
+type Entry struct {
+	Seq   int64
+	Frame []byte // JSON {"type":"odds","eventId":...,"seq":N,"payload":{...}}
+}
+
+// publishScript numera o frame, grava no stream e publica no canal numa única operação,
+// de modo que a ordem do stream e a do Pub/Sub são a mesma.
+// Sequência reiniciada (ex.: chave expirada) descarta o stream antigo
+var publishScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+if seq == 1 then
+  redis.call('DEL', KEYS[2])
+end
+local frame = '{"type":"odds","eventId":' .. cjson.encode(ARGV[1]) .. ',"seq":' .. seq .. ',"payload":' .. ARGV[2] .. '}'
+redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[3], seq .. '-0', 'frame', frame)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+redis.call('PUBLISH', ARGV[5], frame)
+return seq
+`)
+
+// Stream publica e lê os frames numerados de odds no Redis
+type Stream struct {
+	rdb    *redis.Client
+	maxLen int64
+	ttl    time.Duration
+}
+
+// New cria o stream; maxLen (frames mantidos por evento) e ttl são usados apenas na escrita
+func New(rdb *redis.Client, maxLen int64, ttl time.Duration) *Stream {
+	return &Stream{rdb: rdb, maxLen: maxLen, ttl: ttl}
+}
+
+// Publish numera o payload (JSON da atualização) com a próxima sequência do evento,
+// guarda o frame no stream e o publica no Channel; retorna a sequência atribuída
+func (s *Stream) Publish(ctx context.Context, eventID string, payload []byte) (int64, error) {
+	return publishScript.Run(ctx, s.rdb,
+		[]string{seqKey(eventID), streamKey(eventID)},
+		eventID, string(payload), s.maxLen, s.ttl.Milliseconds(), Channel,
+	).Int64()
+}
+
+// Seq retorna o último número de sequência publicado do evento (0 se nenhum)
+func (s *Stream) Seq(ctx context.Context, eventID string) (int64, error) {
+	n, err := s.rdb.Get(ctx, seqKey(eventID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// After retorna os frames do evento com sequência maior que afterSeq ainda mantidos no stream,
+// em ordem; o primeiro pode ser maior que afterSeq+1 se o stream já descartou os anteriores
+func (s *Stream) After(ctx context.Context, eventID string, afterSeq int64) ([]Entry, error) {
+	msgs, err := s.rdb.XRange(ctx, streamKey(eventID), strconv.FormatInt(afterSeq+1, 10)+"-0", "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(msgs))
+	for _, m := range msgs {
+		id, _, _ := strings.Cut(m.ID, "-")
+		seq, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		frame, _ := m.Values["frame"].(string)
+		out = append(out, Entry{Seq: seq, Frame: []byte(frame)})
+	}
+	return out, nil
+}
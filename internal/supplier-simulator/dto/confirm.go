@@ -1,11 +1,20 @@
 package dto
 
+// ConfirmReq cobre o bilhete inteiro: simples (1 seleção) ou acumulada (N seleções)
 type ConfirmReq struct {
-	BetID      string  `json:"betId"`
-	UserID     string  `json:"userId"`
-	EventID    string  `json:"eventId"`
-	StakeCents int64   `json:"stake_cents"`
-	OddValue   float64 `json:"odd_value"`
+	BetID      string       `json:"betId"`
+	UserID     string       `json:"userId"`
+	EventID    string       `json:"eventId,omitempty"`
+	StakeCents int64        `json:"stake_cents"`
+	OddValue   float64      `json:"odd_value"` // odd combinada
+	Legs       []ConfirmLeg `json:"legs,omitempty"`
+}
+
+type ConfirmLeg struct {
+	EventID   string  `json:"eventId"`
+	Market    string  `json:"market"`
+	Selection string  `json:"selection"`
+	OddValue  float64 `json:"odd_value"`
 }
 
 type ConfirmResp struct {
@@ -0,0 +1,245 @@
+package match
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Fases de uma partida simulada
+const (
+	phasePreMatch = iota
+	phaseLive
+	phaseFinished
+)
+
+// suspendTicks é por quantos ticks os mercados ficam suspensos após um gol
+const suspendTicks = 2
+
+// sport é a modalidade de todas as partidas simuladas
+const sport = "football"
+
+// Fixture representa um confronto disponível para agendamento
+type Fixture struct {
+	HomeTeam    string
+	AwayTeam    string
+	Competition string
+}
+
+// Config define a duração das fases de cada partida
+// PreMatch: tempo de odds pré-jogo antes do kickoff
+// Live: tempo real que representa os 90 minutos de jogo
+// Stagger: deslocamento entre o início das partidas iniciais
+type Config struct {
+	Slots    int
+	PreMatch time.Duration
+	Live     time.Duration
+	Stagger  time.Duration
+}
+
+type game struct {
+	eventID   string
+	fixture   Fixture
+	phase     int
+	kickoffAt time.Time
+	endAt     time.Time
+	minute    int
+	homeScore int
+	awayScore int
+	homeRate  float64 // gols esperados do mandante em 90 minutos
+	awayRate  float64 // gols esperados do visitante em 90 minutos
+	suspended int     // ticks restantes de suspensão dos mercados
+	last      []events.OddsUpdate
+}
+
+// Engine simula partidas do pré-jogo ao apito final, gerando odds e resultados
+// Cada slot finalizado é substituído por um novo confronto com um novo EventID
+type Engine struct {
+	mu       sync.Mutex
+	cfg      Config
+	source   string
+	fixtures []Fixture
+	next     int    // próximo fixture a agendar
+	run      string // início da execução, prefixo do EventID
+	seq      int    // sequencial para EventID
+	games    []*game
+	settling []*game // partidas encerradas no tick anterior, com mercados a liquidar
+	version  int64
+}
+
+// NewEngine cria o simulador e agenda as primeiras partidas (MATCH_{início}_001, MATCH_{início}_002, ...)
+func NewEngine(cfg Config, source string, fixtures []Fixture, now time.Time) *Engine {
+	// a versão parte do relógio para continuar crescente após reinícios do simulador;
+	// o odds-processor descarta versões iguais ou menores que a gravada.
+	// O EventID leva o início da execução: após um reinício, novas partidas não reaproveitam
+	// IDs de eventos anteriores (e não liquidam nem reprecificam apostas feitas neles)
+	e := &Engine{cfg: cfg, source: source, fixtures: fixtures, run: now.UTC().Format("20060102150405"), version: now.Unix()}
+	for i := 0; i < cfg.Slots; i++ {
+		e.games = append(e.games, e.schedule(now.Add(time.Duration(i)*cfg.Stagger)))
+	}
+	return e
+}
+
+// schedule cria uma nova partida a partir do próximo fixture
+func (e *Engine) schedule(from time.Time) *game {
+	e.seq++
+	f := e.fixtures[e.next%len(e.fixtures)]
+	e.next++
+	kickoff := from.Add(e.cfg.PreMatch)
+	return &game{
+		eventID:   fmt.Sprintf("MATCH_%s_%03d", e.run, e.seq),
+		fixture:   f,
+		phase:     phasePreMatch,
+		kickoffAt: kickoff,
+		endAt:     kickoff.Add(e.cfg.Live),
+		homeRate:  rnd(1.0, 1.9),
+		awayRate:  rnd(0.8, 1.5),
+	}
+}
+
+// Tick avança todas as partidas até "now" e retorna as odds correntes e as mensagens de placar/resultado
+func (e *Engine) Tick(now time.Time) ([]events.OddsUpdate, []events.MatchResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var updates []events.OddsUpdate
+	var results []events.MatchResult
+
+	// mercados fechados no apito final são liquidados no tick seguinte
+	for _, g := range e.settling {
+		updates = append(updates, e.withStatus(g, events.MarketStatusSettled, now)...)
+	}
+	e.settling = nil
+
+	for i, g := range e.games {
+		switch g.phase {
+		case phasePreMatch:
+			if !now.Before(g.kickoffAt) {
+				g.phase = phaseLive
+				results = append(results, e.result(g, events.MatchStatusKickoff, now))
+			}
+		case phaseLive:
+			if now.Before(g.endAt) {
+				if e.advance(g, now) {
+					results = append(results, e.result(g, events.MatchStatusLive, now))
+					g.suspended = suspendTicks
+				}
+			} else {
+				g.minute = 90
+				g.phase = phaseFinished
+				results = append(results, e.result(g, events.MatchStatusFinished, now))
+				updates = append(updates, e.withStatus(g, events.MarketStatusClosed, now)...)
+				e.settling = append(e.settling, g)
+				// libera o slot para o próximo confronto
+				e.games[i] = e.schedule(now)
+				continue
+			}
+		}
+		updates = append(updates, e.odds(g, now)...)
+	}
+	e.version++
+	return updates, results
+}
+
+// IsOpen indica se o evento está agendado ou em andamento (aceita apostas)
+func (e *Engine) IsOpen(eventID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, g := range e.games {
+		if g.eventID == eventID {
+			return g.phase != phaseFinished
+		}
+	}
+	return false
+}
+
+// advance atualiza o minuto de jogo e sorteia gols; retorna true se o placar mudou
+func (e *Engine) advance(g *game, now time.Time) bool {
+	elapsed := now.Sub(g.kickoffAt)
+	minute := int(elapsed * 90 / e.cfg.Live)
+	played := minute - g.minute
+	g.minute = minute
+
+	// gols distribuídos ao longo dos 90 minutos segundo a média de cada time
+	scored := false
+	for i := 0; i < played; i++ {
+		if rand.Float64() < g.homeRate/90 {
+			g.homeScore++
+			scored = true
+		}
+		if rand.Float64() < g.awayRate/90 {
+			g.awayScore++
+			scored = true
+		}
+	}
+	return scored
+}
+
+// odds gera as odds de todos os mercados da partida; ao vivo, refletem o placar e o tempo restante.
+// Logo após um gol os mercados são publicados como SUSPENDED
+func (e *Engine) odds(g *game, now time.Time) []events.OddsUpdate {
+	left := 1.0
+	if g.phase != phasePreMatch {
+		left = float64(90-g.minute) / 90
+	}
+	status := events.MarketStatusOpen
+	if g.suspended > 0 {
+		status = events.MarketStatusSuspended
+		g.suspended--
+	}
+	var out []events.OddsUpdate
+	for _, m := range markets(g, left) {
+		out = append(out, events.OddsUpdate{
+			EventID:     g.eventID,
+			Sport:       sport,
+			Competition: g.fixture.Competition,
+			HomeTeam:    g.fixture.HomeTeam,
+			AwayTeam:    g.fixture.AwayTeam,
+			Market:      m.name,
+			Line:        m.line,
+			Selections:  selections(m.probs),
+			Status:      status,
+			UpdatedAt:   now.UTC(),
+			Source:      e.source,
+			Version:     e.version,
+		})
+	}
+	g.last = out
+	return out
+}
+
+// withStatus republica os últimos preços da partida com um novo status de mercado
+func (e *Engine) withStatus(g *game, status string, now time.Time) []events.OddsUpdate {
+	out := make([]events.OddsUpdate, 0, len(g.last))
+	for _, u := range g.last {
+		u.Status = status
+		u.UpdatedAt = now.UTC()
+		u.Version = e.version
+		out = append(out, u)
+	}
+	return out
+}
+
+// result monta a mensagem de placar; no apito final inclui o resultado por mercado
+func (e *Engine) result(g *game, status string, now time.Time) events.MatchResult {
+	r := events.MatchResult{
+		Type:      events.MessageTypeMatchResult,
+		EventID:   g.eventID,
+		HomeTeam:  g.fixture.HomeTeam,
+		AwayTeam:  g.fixture.AwayTeam,
+		Status:    status,
+		Minute:    g.minute,
+		HomeScore: g.homeScore,
+		AwayScore: g.awayScore,
+		UpdatedAt: now.UTC(),
+		Source:    e.source,
+	}
+	if status == events.MatchStatusFinished {
+		r.FinishedAt = now.UTC()
+		r.Outcomes = outcomes(g.homeScore, g.awayScore)
+	}
+	return r
+}
@@ -0,0 +1,184 @@
+package match
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/radieske/sports-bet-platform-poc/pkg/contracts/events"
+)
+
+// Parâmetros de precificação do fornecedor
+const (
+	supplierMargin = 0.05 // margem embutida nas odds do fornecedor
+	maxExtraGoals  = 10   // gols adicionais considerados no modelo de Poisson
+	maxScore       = 3    // placar exato é cotado até 3x3; acima disso é "other"
+	minOdd         = 1.01
+	maxOdd         = 501.0
+)
+
+var (
+	overUnderLines = []float64{1.5, 2.5, 3.5}
+	handicapLines  = []float64{-1.5, 1.5} // aplicadas ao mandante
+)
+
+// market é um mercado precificado antes de virar OddsUpdate
+type market struct {
+	name, line string
+	probs      []prob
+}
+
+type prob struct {
+	name string
+	p    float64
+}
+
+// markets precifica todos os mercados da partida. O placar final é modelado como o placar
+// atual mais gols adicionais independentes (Poisson) proporcionais ao tempo restante, de
+// modo que as odds de todos os mercados são coerentes entre si e com o placar ao vivo
+func markets(g *game, left float64) []market {
+	ph := poisson(g.homeRate*left, maxExtraGoals)
+	pa := poisson(g.awayRate*left, maxExtraGoals)
+
+	var (
+		home, draw, away, btts float64
+		over                   = make([]float64, len(overUnderLines))
+		hcp                    = make([]float64, len(handicapLines))
+		score                  = map[string]float64{}
+		other                  float64
+	)
+	for i, pi := range ph {
+		for j, pj := range pa {
+			p := pi * pj
+			h, a := g.homeScore+i, g.awayScore+j
+			switch {
+			case h > a:
+				home += p
+			case h < a:
+				away += p
+			default:
+				draw += p
+			}
+			if h > 0 && a > 0 {
+				btts += p
+			}
+			for k, line := range overUnderLines {
+				if float64(h+a) > line {
+					over[k] += p
+				}
+			}
+			for k, line := range handicapLines {
+				if float64(h)+line > float64(a) {
+					hcp[k] += p
+				}
+			}
+			if h <= maxScore && a <= maxScore {
+				score[scoreName(h, a)] += p
+			} else {
+				other += p
+			}
+		}
+	}
+
+	out := []market{
+		{name: events.MarketMatchOdds, probs: []prob{{"home", home}, {"draw", draw}, {"away", away}}},
+		{name: events.MarketBTTS, probs: []prob{{"yes", btts}, {"no", 1 - btts}}},
+	}
+	for k, line := range overUnderLines {
+		out = append(out, market{name: events.MarketOverUnder, line: lineName(line),
+			probs: []prob{{"over", over[k]}, {"under", 1 - over[k]}}})
+	}
+	for k, line := range handicapLines {
+		out = append(out, market{name: events.MarketHandicap, line: lineName(line),
+			probs: []prob{{"home", hcp[k]}, {"away", 1 - hcp[k]}}})
+	}
+	cs := market{name: events.MarketCorrectScore}
+	for h := 0; h <= maxScore; h++ {
+		for a := 0; a <= maxScore; a++ {
+			cs.probs = append(cs.probs, prob{scoreName(h, a), score[scoreName(h, a)]})
+		}
+	}
+	cs.probs = append(cs.probs, prob{"other", other})
+	out = append(out, cs)
+	return out
+}
+
+// selections converte probabilidades em odds com margem e ruído; seleções
+// impossíveis (ex.: placar já superado) não são ofertadas
+func selections(probs []prob) []events.Selection {
+	out := make([]events.Selection, 0, len(probs))
+	for _, x := range probs {
+		if x.p < 1e-6 {
+			continue
+		}
+		odd := 1 / (x.p * (1 + supplierMargin)) * (1 + rnd(-0.02, 0.02))
+		odd = math.Max(minOdd, math.Min(maxOdd, odd))
+		out = append(out, events.Selection{Name: x.name, Odd: math.Round(odd*100) / 100})
+	}
+	return out
+}
+
+// outcomes retorna a seleção vencedora de cada mercado cotado para o placar final
+func outcomes(home, away int) map[string]string {
+	out := map[string]string{
+		events.MarketMatchOdds: winner1x2(home, away),
+		events.MarketBTTS:      "no",
+	}
+	if home > 0 && away > 0 {
+		out[events.MarketBTTS] = "yes"
+	}
+	for _, line := range overUnderLines {
+		k := events.MarketKey(events.MarketOverUnder, lineName(line))
+		out[k] = "under"
+		if float64(home+away) > line {
+			out[k] = "over"
+		}
+	}
+	for _, line := range handicapLines {
+		k := events.MarketKey(events.MarketHandicap, lineName(line))
+		out[k] = "away"
+		if float64(home)+line > float64(away) {
+			out[k] = "home"
+		}
+	}
+	out[events.MarketCorrectScore] = "other"
+	if home <= maxScore && away <= maxScore {
+		out[events.MarketCorrectScore] = scoreName(home, away)
+	}
+	return out
+}
+
+func winner1x2(home, away int) string {
+	switch {
+	case home > away:
+		return "home"
+	case home < away:
+		return "away"
+	default:
+		return "draw"
+	}
+}
+
+// poisson retorna P(X = k) para k em [0, n]; a cauda acima de n é somada em n
+func poisson(lambda float64, n int) []float64 {
+	out := make([]float64, n+1)
+	p := math.Exp(-lambda)
+	sum := 0.0
+	for k := 0; k < n; k++ {
+		out[k] = p
+		sum += p
+		p *= lambda / float64(k+1)
+	}
+	out[n] = math.Max(0, 1-sum)
+	return out
+}
+
+func scoreName(home, away int) string { return fmt.Sprintf("%d-%d", home, away) }
+
+func lineName(line float64) string { return strconv.FormatFloat(line, 'f', -1, 64) }
+
+// rnd gera número aleatório entre min e max
+func rnd(min, max float64) float64 {
+	return (rand.Float64() * (max - min)) + min
+}
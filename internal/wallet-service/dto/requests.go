@@ -12,6 +12,17 @@ type ReserveRequest struct {
 	ExternalRef string `json:"external_ref"` // ex: betId
 }
 
+// ReserveBatchRequest reserva o total de várias referências de uma vez (ex.: linhas de uma aposta sistema)
+type ReserveBatchRequest struct {
+	UserID string             `json:"userId"`
+	Items  []ReserveBatchItem `json:"items"`
+}
+
+type ReserveBatchItem struct {
+	ExternalRef string `json:"external_ref"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
 type CommitRequest struct {
 	UserID      string `json:"userId"`
 	ExternalRef string `json:"external_ref"`
@@ -21,3 +32,10 @@ type RefundRequest struct {
 	UserID      string `json:"userId"`
 	ExternalRef string `json:"external_ref"`
 }
+
+type PayoutRequest struct {
+	UserID      string `json:"userId"`
+	AmountCents int64  `json:"amount_cents"`
+	ExternalRef string `json:"external_ref"`    // ex: bet-settle:{betId}
+	BetID       string `json:"betId,omitempty"` // aposta relacionada no ledger
+}
@@ -10,3 +10,14 @@ type ReservationResponse struct {
 	ReservationID string `json:"reservation_id"`
 	Status        string `json:"status"`
 }
+
+type ReserveBatchResponse struct {
+	Reservations []ReservationResponse `json:"reservations"`
+	TotalCents   int64                 `json:"total_cents"`
+}
+
+type PayoutResponse struct {
+	UserID       string `json:"userId"`
+	BalanceCents int64  `json:"balance_cents"`
+	Status       string `json:"status"`
+}
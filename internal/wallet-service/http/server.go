@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"go.uber.org/zap"
 
 	"github.com/radieske/sports-bet-platform-poc/internal/wallet-service/dto"
+	"github.com/radieske/sports-bet-platform-poc/internal/wallet-service/repo"
 )
 
 // Repo define a interface de operações de carteira usadas pelo handler HTTP
@@ -16,8 +18,10 @@ type Repo interface {
 	GetOrCreateWallet(ctx context.Context, userID string) (walletID string, balance int64, err error)
 	Deposit(ctx context.Context, userID string, amount int64, externalRef string) (walletID string, newBalance int64, err error)
 	Reserve(ctx context.Context, userID string, amount int64, externalRef string) (reservationID string, err error)
+	ReserveBatch(ctx context.Context, userID string, items []repo.ReserveItem) (reservationIDs []string, err error)
 	Commit(ctx context.Context, userID, externalRef string) error
 	Refund(ctx context.Context, userID, externalRef string) error
+	Payout(ctx context.Context, userID string, amount int64, externalRef, betID string) (newBalance int64, err error)
 }
 
 // Server expõe endpoints HTTP para operações de carteira (wallet)
@@ -32,11 +36,13 @@ func NewServer(log *zap.Logger, repo Repo) *Server { return &Server{log: log, re
 // Router retorna o mux HTTP com as rotas da API de wallet
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/wallet", s.getWallet)       // GET ?userId=...
-	mux.HandleFunc("/wallet/deposit", s.deposit) // POST
-	mux.HandleFunc("/wallet/reserve", s.reserve) // POST
-	mux.HandleFunc("/wallet/commit", s.commit)   // POST
-	mux.HandleFunc("/wallet/refund", s.refund)   // POST
+	mux.HandleFunc("/wallet", s.getWallet)                  // GET ?userId=...
+	mux.HandleFunc("/wallet/deposit", s.deposit)            // POST
+	mux.HandleFunc("/wallet/reserve", s.reserve)            // POST
+	mux.HandleFunc("/wallet/reserve-batch", s.reserveBatch) // POST
+	mux.HandleFunc("/wallet/commit", s.commit)              // POST
+	mux.HandleFunc("/wallet/refund", s.refund)              // POST
+	mux.HandleFunc("/wallet/payout", s.payout)              // POST
 	return mux
 }
 
@@ -97,6 +103,43 @@ func (s *Server) reserve(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, dto.ReservationResponse{ReservationID: resID, Status: "PENDING"})
 }
 
+// reserveBatch reserva o total de um lote de referências de forma atômica
+func (s *Server) reserveBatch(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReserveBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || len(req.Items) == 0 {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	items := make([]repo.ReserveItem, 0, len(req.Items))
+	var total int64
+	for _, it := range req.Items {
+		if it.AmountCents <= 0 || it.ExternalRef == "" {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		items = append(items, repo.ReserveItem{ExternalRef: it.ExternalRef, AmountCents: it.AmountCents})
+		total += it.AmountCents
+	}
+	ids, err := s.repo.ReserveBatch(r.Context(), req.UserID, items)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "wallet not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	resp := dto.ReserveBatchResponse{TotalCents: total}
+	for _, id := range ids {
+		resp.Reservations = append(resp.Reservations, dto.ReservationResponse{ReservationID: id, Status: "PENDING"})
+	}
+	writeJSON(w, resp)
+}
+
 // commit efetiva uma reserva de saldo
 func (s *Server) commit(w http.ResponseWriter, r *http.Request) {
 	var req dto.CommitRequest
@@ -109,6 +152,10 @@ func (s *Server) commit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := s.repo.Commit(r.Context(), req.UserID, req.ExternalRef); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			http.Error(w, "reservation not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
@@ -128,6 +175,10 @@ func (s *Server) refund(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := s.repo.Refund(r.Context(), req.UserID, req.ExternalRef); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			http.Error(w, "reservation not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
@@ -135,6 +186,29 @@ func (s *Server) refund(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"REFUNDED"}`))
 }
 
+// payout credita um prêmio (ou devolução de aposta anulada) na carteira do usuário
+func (s *Server) payout(w http.ResponseWriter, r *http.Request) {
+	var req dto.PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || req.AmountCents <= 0 || req.ExternalRef == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	bal, err := s.repo.Payout(r.Context(), req.UserID, req.AmountCents, req.ExternalRef, req.BetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "wallet not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, dto.PayoutResponse{UserID: req.UserID, BalanceCents: bal, Status: "CREDITED"})
+}
+
 // writeJSON serializa e envia resposta JSON
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -139,6 +139,76 @@ func (p *Postgres) Reserve(ctx context.Context, userID string, amount int64, ext
 	return reservationID, nil
 }
 
+// ReserveItem é uma reserva de um lote (ex.: uma linha de aposta sistema)
+type ReserveItem struct {
+	ExternalRef string
+	AmountCents int64
+}
+
+// ReserveBatch reserva o total de um lote em uma única transação: o saldo é verificado
+// contra a soma dos itens e cada item vira uma reserva PENDING própria (por external_ref),
+// que pode ser efetivada ou estornada individualmente. Itens já reservados são reaproveitados.
+func (p *Postgres) ReserveBatch(ctx context.Context, userID string, items []ReserveItem) (reservationIDs []string, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var walletID string
+	var balance int64
+	if err = tx.QueryRowContext(ctx, `SELECT id, balance_cents FROM wallets WHERE user_id=$1 FOR UPDATE`, userID).Scan(&walletID, &balance); err != nil {
+		return nil, err
+	}
+
+	// Idempotência: separa os itens que já possuem reserva
+	reservationIDs = make([]string, len(items))
+	var total int64
+	for i, it := range items {
+		var exists string
+		err = tx.QueryRowContext(ctx, `SELECT id FROM wallet_reservations WHERE wallet_id=$1 AND external_ref=$2`, walletID, it.ExternalRef).Scan(&exists)
+		if err == nil {
+			reservationIDs[i] = exists
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+		total += it.AmountCents
+	}
+	if total == 0 {
+		return reservationIDs, nil
+	}
+	if balance < total {
+		return nil, ErrInsufficientFunds
+	}
+
+	// Debita o total de uma vez (bloqueio)
+	if _, err = tx.ExecContext(ctx, `UPDATE wallets SET balance_cents = balance_cents - $1, version = version + 1 WHERE id=$2`, total, walletID); err != nil {
+		return nil, err
+	}
+
+	for i, it := range items {
+		if reservationIDs[i] != "" {
+			continue
+		}
+		reservationIDs[i] = uuid.New().String()
+		if _, err = tx.ExecContext(ctx, `INSERT INTO wallet_reservations(id, wallet_id, external_ref, amount_cents, status) VALUES($1,$2,$3,$4,'PENDING')`,
+			reservationIDs[i], walletID, it.ExternalRef, it.AmountCents); err != nil {
+			return nil, err
+		}
+		if _, err = tx.ExecContext(ctx, `INSERT INTO wallet_ledger(wallet_id, operation_type, amount_cents, description, related_bet_id)
+			VALUES($1,'RESERVE',$2,$3,$4)`,
+			walletID, it.AmountCents, "reserve:"+it.ExternalRef, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reservationIDs, nil
+}
+
 // Commit efetiva uma reserva, marcando como COMMITTED e registrando débito no ledger
 // Idempotente: se já estiver committed, não faz nada
 func (p *Postgres) Commit(ctx context.Context, userID, externalRef string) error {
@@ -219,3 +289,50 @@ func (p *Postgres) Refund(ctx context.Context, userID, externalRef string) error
 
 	return tx.Commit()
 }
+
+// Payout credita um valor na carteira (prêmio ou devolução) e registra CREDIT no ledger
+// Idempotente por external_ref: um crédito já registrado não é repetido
+func (p *Postgres) Payout(ctx context.Context, userID string, amount int64, externalRef, betID string) (newBalance int64, err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var walletID string
+	if err = tx.QueryRowContext(ctx, `SELECT id FROM wallets WHERE user_id=$1 FOR UPDATE`, userID).Scan(&walletID); err != nil {
+		return 0, err
+	}
+
+	// Idempotência: o lock na carteira serializa créditos concorrentes do mesmo usuário
+	var exists bool
+	if err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM wallet_ledger WHERE wallet_id=$1 AND operation_type='CREDIT' AND description=$2)`,
+		walletID, "payout:"+externalRef).Scan(&exists); err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		if _, err = tx.ExecContext(ctx, `UPDATE wallets SET balance_cents = balance_cents + $1, version = version + 1 WHERE id=$2`, amount, walletID); err != nil {
+			return 0, err
+		}
+
+		var related any
+		if betID != "" {
+			related = betID
+		}
+		if _, err = tx.ExecContext(ctx, `INSERT INTO wallet_ledger(wallet_id, operation_type, amount_cents, description, related_bet_id)
+			VALUES($1,'CREDIT',$2,$3,$4)`, walletID, amount, "payout:"+externalRef, related); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.QueryRowContext(ctx, `SELECT balance_cents FROM wallets WHERE id=$1`, walletID).Scan(&newBalance); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return newBalance, nil
+}
@@ -1,13 +1,37 @@
 package events
 
+// Evento emitido pelo bet-service quando uma aposta (simples ou acumulada) é criada.
+// Legs sempre traz as seleções do bilhete; em apostas simples os campos
+// EventID/Market/Selection de topo repetem a única seleção. Linhas de apostas
+// sistema são publicadas como apostas independentes, ligadas pelo SlipID.
 type BetPlaced struct {
-	BetID       string  `json:"betId"`
-	UserID      string  `json:"userId"`
-	EventID     string  `json:"eventId"`
-	Market      string  `json:"market"`
-	Selection   string  `json:"selection"`
-	StakeCents  int64   `json:"stakeCents"`
-	OddValue    float64 `json:"oddValue"`
-	ReservedRef string  `json:"reservedRef"` // external_ref usado na reserva da carteira (betID)
-	TsUnixMs    int64   `json:"tsUnixMs"`
+	BetID       string      `json:"betId"`
+	UserID      string      `json:"userId"`
+	BetType     string      `json:"betType,omitempty"` // "SINGLE" | "ACCUMULATOR"
+	SlipID      string      `json:"slipId,omitempty"`  // bilhete pai quando a aposta é uma linha de aposta sistema
+	EventID     string      `json:"eventId,omitempty"`
+	Market      string      `json:"market,omitempty"`
+	Selection   string      `json:"selection,omitempty"`
+	StakeCents  int64       `json:"stakeCents"`
+	OddValue    float64     `json:"oddValue"` // odd combinada do bilhete
+	Legs        []BetLegRef `json:"legs,omitempty"`
+	ReservedRef string      `json:"reservedRef"` // external_ref usado na reserva da carteira (betID)
+	TsUnixMs    int64       `json:"tsUnixMs"`
+}
+
+// BetLegRef é uma seleção de um bilhete
+type BetLegRef struct {
+	EventID   string  `json:"eventId"`
+	Market    string  `json:"market"`
+	Selection string  `json:"selection"`
+	OddValue  float64 `json:"oddValue"`
+}
+
+// SelectionLegs retorna as seleções do bilhete, derivando a única seleção
+// dos campos de topo em eventos antigos (sem Legs)
+func (e BetPlaced) SelectionLegs() []BetLegRef {
+	if len(e.Legs) > 0 || e.EventID == "" {
+		return e.Legs
+	}
+	return []BetLegRef{{EventID: e.EventID, Market: e.Market, Selection: e.Selection, OddValue: e.OddValue}}
 }
@@ -0,0 +1,36 @@
+package events
+
+import "time"
+
+// MessageTypeMatchResult identifica mensagens de placar/resultado no WebSocket do fornecedor
+const MessageTypeMatchResult = "match_result"
+
+// Status possíveis de uma partida
+const (
+	MatchStatusKickoff   = "KICKOFF"
+	MatchStatusLive      = "LIVE"
+	MatchStatusFinished  = "FINISHED"
+	MatchStatusCancelled = "CANCELLED"
+)
+
+// Evento publicado no tópico "match_results" a cada mudança de placar ou de estado da partida
+// Apenas FINISHED e CANCELLED são definitivos e disparam a liquidação das apostas
+type MatchResult struct {
+	Type       string            `json:"type"` // "match_result"
+	EventID    string            `json:"event_id"`
+	HomeTeam   string            `json:"home_team"`
+	AwayTeam   string            `json:"away_team"`
+	Status     string            `json:"status"` // "KICKOFF" | "LIVE" | "FINISHED" | "CANCELLED"
+	Minute     int               `json:"minute"`
+	HomeScore  int               `json:"home_score"`
+	AwayScore  int               `json:"away_score"`
+	Outcomes   map[string]string `json:"outcomes,omitempty"` // mercado -> seleção vencedora (ex: "1x2" -> "home")
+	FinishedAt time.Time         `json:"finished_at,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Source     string            `json:"source"` // "supplier-simulator"
+}
+
+// IsFinal indica se o resultado é definitivo
+func (r MatchResult) IsFinal() bool {
+	return r.Status == MatchStatusFinished || r.Status == MatchStatusCancelled
+}
@@ -1,21 +1,126 @@
 package events
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
-// Evento publicado no tópico "odds_updates"
-type Odds struct {
-	Home float64 `json:"home"`
-	Draw float64 `json:"draw"`
-	Away float64 `json:"away"`
+// Mercados publicados pelo fornecedor
+const (
+	MarketMatchOdds    = "1x2"           // home | draw | away
+	MarketOverUnder    = "over_under"    // over | under (linha = total de gols, ex: "2.5")
+	MarketBTTS         = "btts"          // yes | no (ambas marcam)
+	MarketCorrectScore = "correct_score" // "{home}-{away}" (ex: "2-1") | other
+	MarketHandicap     = "handicap"      // home | away (linha aplicada ao mandante, ex: "-1.5")
+)
+
+// Status de um mercado. Apostas só são aceitas em mercados OPEN; o fornecedor suspende
+// o mercado em lances decisivos (ex.: gol), fecha no apito final e liquida com o resultado
+const (
+	MarketStatusOpen      = "OPEN"
+	MarketStatusSuspended = "SUSPENDED"
+	MarketStatusClosed    = "CLOSED"
+	MarketStatusSettled   = "SETTLED"
+)
+
+// Selection é uma seleção de um mercado com sua odd
+type Selection struct {
+	Name string  `json:"name"`
+	Odd  float64 `json:"odd"`
 }
 
+// Evento publicado no tópico "odds_updates": preço atual de um mercado de um evento.
+// O mercado é identificado por (event_id, market, line); line fica vazio em mercados sem linha
 type OddsUpdate struct {
-	EventID   string    `json:"event_id"`
-	HomeTeam  string    `json:"home_team"`
-	AwayTeam  string    `json:"away_team"`
-	Market    string    `json:"market"` // "1x2"
-	Odds      Odds      `json:"odds"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Source    string    `json:"source"`  // "supplier-simulator"
-	Version   int       `json:"version"` // incrementado a cada atualização
+	EventID     string      `json:"event_id"`
+	Sport       string      `json:"sport,omitempty"`       // ex: "football"
+	Competition string      `json:"competition,omitempty"` // ex: "brasileirao-serie-a"
+	HomeTeam    string      `json:"home_team"`
+	AwayTeam    string      `json:"away_team"`
+	Market      string      `json:"market"`         // "1x2" | "over_under" | "btts" | "correct_score" | "handicap"
+	Line        string      `json:"line,omitempty"` // ex: "2.5" (over_under), "-1.5" (handicap)
+	Selections  []Selection `json:"selections"`
+	Status      string      `json:"status,omitempty"` // OPEN | SUSPENDED | CLOSED | SETTLED (ausente = OPEN)
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Source      string      `json:"source"`  // "supplier-simulator"
+	Version     int64       `json:"version"` // incrementado a cada atualização
+}
+
+// MarketKey identifica o mercado dentro do evento (ex: "1x2", "over_under:2.5").
+// É o valor usado no campo market das apostas
+func (u OddsUpdate) MarketKey() string { return MarketKey(u.Market, u.Line) }
+
+// MarketStatus retorna o status do mercado, tratando ausente como OPEN
+func (u OddsUpdate) MarketStatus() string { return NormalizeMarketStatus(u.Status) }
+
+// Price retorna a odd de uma seleção do mercado
+func (u OddsUpdate) Price(name string) (float64, bool) {
+	for _, s := range u.Selections {
+		if strings.EqualFold(s.Name, name) {
+			return s.Odd, true
+		}
+	}
+	return 0, false
+}
+
+// MarketKey monta a chave do mercado a partir do nome e da linha normalizados
+func MarketKey(market, line string) string {
+	market = strings.ToLower(strings.TrimSpace(market))
+	if line = NormalizeLine(line); line != "" {
+		return market + ":" + line
+	}
+	return market
+}
+
+// NormalizeMarket padroniza a chave do mercado e unifica os nomes aceitos
+// (ex.: "MATCH_ODDS" -> "1x2", "Over_Under:2.50" -> "over_under:2.5")
+func NormalizeMarket(key string) string {
+	m, line := SplitMarketKey(key)
+	switch m {
+	case "match_odds", "winner":
+		m = MarketMatchOdds
+	}
+	return MarketKey(m, line)
+}
+
+// NormalizeSelection unifica as notações aceitas ("1"|"home", "x"|"draw", "2"|"away")
+func NormalizeSelection(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "1":
+		return "home"
+	case "x":
+		return "draw"
+	case "2":
+		return "away"
+	}
+	return s
+}
+
+// NormalizeMarketStatus padroniza o status do mercado; vazio (produtores antigos) é OPEN
+func NormalizeMarketStatus(s string) string {
+	if s = strings.ToUpper(strings.TrimSpace(s)); s == "" {
+		return MarketStatusOpen
+	}
+	return s
+}
+
+// SplitMarketKey separa a chave em nome do mercado e linha ("over_under:2.5" -> "over_under", "2.5")
+func SplitMarketKey(key string) (market, line string) {
+	market, line, _ = strings.Cut(strings.ToLower(strings.TrimSpace(key)), ":")
+	return market, NormalizeLine(line)
+}
+
+// NormalizeLine padroniza a linha numérica ("+1.50" -> "1.5"); linhas não numéricas são mantidas
+func NormalizeLine(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ""
+	}
+	f, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return line
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
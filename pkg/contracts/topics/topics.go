@@ -4,6 +4,9 @@ const (
 	// Odds
 	OddsUpdates = "odds_updates"
 
+	// Resultados de partidas
+	MatchResults = "match_results"
+
 	// Bets
 	BetPlaced    = "bet_placed"
 	BetConfirmed = "bet_confirmed"